@@ -0,0 +1,43 @@
+package fuzzyfinder
+
+import (
+	"github.com/gdamore/tcell/v2"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// minAnnotatedTextWidth is the minimum width of an item text next to an
+// annotation. The annotation is truncated to keep it.
+const minAnnotatedTextWidth = 4
+
+// drawAnnotation draws the annotation of the item right-aligned in the row.
+// The item lines end at maxWidth. It returns the width which is left for the
+// item text.
+func (f *finder) drawAnnotation(idx, row, maxWidth int, cursor bool) int {
+	ann := f.opt.annotation(idx)
+	if ann == "" {
+		return maxWidth
+	}
+
+	// The cursor, a space between the text and the annotation, and the
+	// minimum text.
+	limit := maxWidth - 2 - 1 - minAnnotatedTextWidth
+	if limit <= 0 {
+		return maxWidth
+	}
+	if runewidth.StringWidth(ann) > limit {
+		ann = runewidth.Truncate(ann, limit, "..")
+	}
+
+	style := tcell.StyleDefault.Dim(true)
+	if cursor {
+		style = style.Background(tcell.ColorBlack)
+	}
+	term := f.listPane()
+	start := maxWidth - runewidth.StringWidth(ann)
+	w := start
+	for _, r := range ann {
+		term.SetContent(w, row, r, nil, style)
+		w += runewidth.RuneWidth(r)
+	}
+	return start - 1
+}
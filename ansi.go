@@ -0,0 +1,89 @@
+package fuzzyfinder
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ktr0731/go-ansisgr"
+)
+
+// stripANSI returns s without SGR sequences.
+func stripANSI(s string) string {
+	if !strings.ContainsRune(s, 0x1b) {
+		return s
+	}
+	var b strings.Builder
+	iter := ansisgr.NewIterator(s)
+	for {
+		r, _, ok := iter.Next()
+		if !ok {
+			return b.String()
+		}
+		b.WriteRune(r)
+	}
+}
+
+// stripANSIAll returns items without SGR sequences.
+func stripANSIAll(items []string) []string {
+	res := make([]string, len(items))
+	for i, s := range items {
+		res[i] = stripANSI(s)
+	}
+	return res
+}
+
+// ansiRunes returns the runes of s without SGR sequences and their styles.
+func ansiRunes(s string) ([]rune, []tcell.Style) {
+	var (
+		runes  []rune
+		styles []tcell.Style
+	)
+	iter := ansisgr.NewIterator(s)
+	for {
+		r, style, ok := iter.Next()
+		if !ok {
+			return runes, styles
+		}
+		runes = append(runes, r)
+		styles = append(styles, sgrStyle(style))
+	}
+}
+
+// sgrStyle converts a style parsed from SGR sequences to tcell.Style.
+func sgrStyle(s ansisgr.Style) tcell.Style {
+	style := tcell.StyleDefault
+	if color, ok := s.Foreground(); ok {
+		style = style.Foreground(sgrColor(color, 30))
+	}
+	if color, ok := s.Background(); ok {
+		style = style.Background(sgrColor(color, 40))
+	}
+	return style.
+		Bold(s.Bold()).
+		Dim(s.Dim()).
+		Italic(s.Italic()).
+		Underline(s.Underline()).
+		Blink(s.Blink()).
+		Reverse(s.Reverse()).
+		StrikeThrough(s.Strikethrough())
+}
+
+// sgrColor converts a color parsed from SGR sequences to tcell.Color. base is
+// the parameter of the first 16-color, which is 30 for foreground colors and 40
+// for background ones.
+func sgrColor(color ansisgr.Color, base int) tcell.Color {
+	switch color.Mode() {
+	case ansisgr.Mode16:
+		v := color.Value()
+		if v >= base+60 {
+			// Bright colors such as 90-97 and 100-107.
+			return tcell.PaletteColor(v - base - 60 + 8)
+		}
+		return tcell.PaletteColor(v - base)
+	case ansisgr.Mode256:
+		return tcell.PaletteColor(color.Value())
+	default:
+		r, g, b := color.RGB()
+		return tcell.NewRGBColor(int32(r), int32(g), int32(b))
+	}
+}
@@ -0,0 +1,209 @@
+package fuzzyfinder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// runCommand runs the command passed by WithCommandSource in the background
+// and replaces the items with the lines of its stdout as they are read. The
+// running command is killed. It must be called while stateMu is locked.
+func (f *finder) runCommand(ctx context.Context) {
+	f.cancelLoader()
+	ctx, cancel := context.WithCancel(ctx)
+	f.cancelReload = cancel
+	gen := f.state.reloadGen
+	f.state.reloading = true
+	f.state.errMsg = ""
+
+	prevDone := f.commandDone
+	done := make(chan struct{})
+	f.commandDone = done
+
+	go func() {
+		defer close(done)
+		defer cancel()
+
+		if prevDone != nil {
+			// Wait for the killed command so that its items are never applied
+			// after the ones of this command.
+			<-prevDone
+		}
+
+		spinnerDone := make(chan struct{})
+		defer close(spinnerDone)
+		go f.animateSpinner(spinnerDone)
+
+		err := f.execCommand(ctx, gen)
+
+		f.stateMu.Lock()
+		if gen != f.state.reloadGen || ctx.Err() != nil {
+			// The command is run again, or the finder is closed.
+			f.stateMu.Unlock()
+			return
+		}
+		f.state.reloading = false
+		if err != nil {
+			f.state.errMsg = err.Error()
+		}
+		f.stateMu.Unlock()
+		f.draw(0)
+	}()
+}
+
+// execCommand runs the command and applies the lines of its stdout until it
+// exits or ctx is done. The first lines replace the current items, and the
+// following ones are appended.
+func (f *finder) execCommand(ctx context.Context, gen int) error {
+	cmd := exec.CommandContext(ctx, f.opt.commandName, f.opt.commandArgs...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the stdout of the command")
+	}
+	stderr := &stderrWriter{f: f, gen: gen}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "failed to start the command")
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		s := bufio.NewScanner(stdout)
+		s.Buffer(nil, maxReaderLineSize)
+		for s.Scan() {
+			select {
+			case <-ctx.Done():
+				return
+			case lines <- s.Text():
+			}
+		}
+	}()
+
+	reset := true
+	receiveBatches(ctx, lines, func(items []string) {
+		c := ItemChange{Kind: ItemsAppended, Items: items}
+		if reset {
+			c.Kind = itemsReset
+			reset = false
+		}
+		f.applyChanges([]ItemChange{c})
+	})
+	if reset && ctx.Err() == nil {
+		// The command printed nothing.
+		f.applyChanges([]ItemChange{{Kind: itemsReset}})
+	}
+
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			// The command is killed by the finder.
+			return nil
+		}
+		if line := stderr.lastLine(); line != "" {
+			return errors.Errorf("%s: %s", err, line)
+		}
+		return errors.Wrap(err, "command failed")
+	}
+	return nil
+}
+
+// stderrWriter displays the last line written to the stderr of the command in
+// the header area.
+type stderrWriter struct {
+	f   *finder
+	gen int
+
+	mu   sync.Mutex
+	buf  []byte
+	last string
+}
+
+func (w *stderrWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.buf = append(w.buf, p...)
+	var updated bool
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i == -1 {
+			break
+		}
+		if line := strings.TrimSpace(string(w.buf[:i])); line != "" {
+			w.last = line
+			updated = true
+		}
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) > maxReaderLineSize {
+		// Discard a too long line.
+		w.buf = nil
+	}
+	last := w.last
+	w.mu.Unlock()
+
+	if updated {
+		w.f.stateMu.Lock()
+		if w.gen == w.f.state.reloadGen {
+			w.f.state.errMsg = last
+		}
+		w.f.stateMu.Unlock()
+		w.f.draw(0)
+	}
+	return len(p), nil
+}
+
+// lastLine returns the last line written to stderr, including the one which
+// doesn't end with a newline.
+func (w *stderrWriter) lastLine() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if line := strings.TrimSpace(string(w.buf)); line != "" {
+		return line
+	}
+	return w.last
+}
+
+// FindCommand displays a UI that provides fuzzy finding against the lines of
+// the stdout of the command named name with args, like WithCommandSource.
+// FindCommand returns the selected line.
+//
+// FindCommand returns ErrAbort if a call to FindCommand is finished with no
+// selection.
+func FindCommand(name string, args []string, opts ...Option) (string, error) {
+	f := newFinder()
+	return f.FindCommand(name, args, opts...)
+}
+
+func (f *finder) FindCommand(name string, args []string, opts ...Option) (string, error) {
+	res, err := f.findCommand(name, args, opts)
+	if err != nil {
+		return "", err
+	}
+	return res[0], nil
+}
+
+// FindMultiCommand is nearly the same as FindCommand. The only difference from
+// FindCommand is that the user can select multiple items at once, by using the
+// tab key.
+func FindMultiCommand(name string, args []string, opts ...Option) ([]string, error) {
+	f := newFinder()
+	return f.FindMultiCommand(name, args, opts...)
+}
+
+func (f *finder) FindMultiCommand(name string, args []string, opts ...Option) ([]string, error) {
+	opts = append(opts, withMulti())
+	return f.findCommand(name, args, opts)
+}
+
+func (f *finder) findCommand(name string, args []string, opts []Option) ([]string, error) {
+	if name == "" {
+		return nil, errors.New("name must not be empty")
+	}
+	_, res, err := f.findStrings(append(opts, WithCommandSource(name, args...)))
+	return res, err
+}
@@ -0,0 +1,206 @@
+package fuzzyfinder_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/go-cmp/cmp"
+	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
+)
+
+// helperCommand returns the arguments of WithCommandSource which run
+// TestHelperProcess with args instead of a shell, so that the tests don't
+// depend on the commands of the platform.
+func helperCommand(args ...string) (string, []string) {
+	return os.Args[0], append([]string{"-test.run=^TestHelperProcess$", "--"}, args...)
+}
+
+// TestHelperProcess isn't a real test. It is run as the command of the
+// command source tests. The first argument is one of the following:
+//
+//	print <line>...  prints each line
+//	fail             prints "foo", writes an error to stderr and exits with 3
+//	count <file>     prints the number of lines in file and appends a line
+//	sleep <line>     prints line and sleeps
+func TestHelperProcess(*testing.T) {
+	args := flag.Args()
+	if len(args) == 0 {
+		return
+	}
+
+	switch args[0] {
+	case "print":
+		for _, l := range args[1:] {
+			fmt.Println(l)
+		}
+	case "fail":
+		fmt.Println("foo")
+		fmt.Fprintln(os.Stderr, "no such file")
+		os.Exit(3)
+	case "count":
+		b, _ := os.ReadFile(args[1])
+		fmt.Printf("run %d\n", bytes.Count(b, []byte("\n")))
+		f, err := os.OpenFile(args[1], os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Fprintln(f, "x")
+		f.Close()
+	case "sleep":
+		fmt.Println(args[1])
+		time.Sleep(3 * time.Second)
+	}
+	os.Exit(0)
+}
+
+// commandStep is a step of a command source test. events are sent once lines
+// are displayed, since the output of the command arrives asynchronously.
+type commandStep struct {
+	lines  []string
+	events []input
+}
+
+// sendSteps sends the events of steps in order.
+func sendSteps(t *testing.T, term *fuzzyfinder.TerminalMock, steps ...commandStep) {
+	t.Helper()
+
+	go func() {
+		for _, s := range steps {
+			if !term.WaitForLines(s.lines...) {
+				t.Errorf("%q must be displayed", s.lines)
+			}
+			term.SetEventsV2(keys(s.events...)...)
+		}
+	}()
+}
+
+func TestFind_WithCommandSource(t *testing.T) {
+	t.Parallel()
+
+	enter := input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}
+	up := input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}
+
+	// Each run prints one more line than the previous one.
+	counter := filepath.Join(t.TempDir(), "counter")
+	cases := map[string]struct {
+		args     []string
+		opts     []fuzzyfinder.Option
+		steps    []commandStep
+		expected int
+	}{
+		"normal": {
+			args:     []string{"print", "foo", "bar", "baz"},
+			steps:    []commandStep{{lines: []string{"3/3"}, events: []input{up, enter}}},
+			expected: 1,
+		},
+		"error": {
+			args:     []string{"fail"},
+			steps:    []commandStep{{lines: []string{"exit status 3: no such file"}, events: []input{enter}}},
+			expected: 0,
+		},
+		"reload": {
+			args: []string{"count", counter},
+			opts: []fuzzyfinder.Option{fuzzyfinder.WithReload("ctrl-r", nil)},
+			steps: []commandStep{
+				{lines: []string{"> run 0", "1/1"}, events: []input{{tcell.KeyCtrlR, 'R', tcell.ModCtrl}}},
+				{lines: []string{"> run 1", "1/1"}, events: []input{enter}},
+			},
+			expected: 0,
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if name == "reload" {
+				if err := os.WriteFile(counter, nil, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			sendSteps(t, term, c.steps...)
+
+			assertWithGolden(t, func(t *testing.T) string {
+				name, args := helperCommand(c.args...)
+				opts := append([]fuzzyfinder.Option{fuzzyfinder.WithCommandSource(name, args...)}, c.opts...)
+				idx, err := f.Find(nil, nil, opts...)
+				if err != nil {
+					t.Fatalf("Find must not return an error, but got '%s'", err)
+				}
+				if idx != c.expected {
+					t.Errorf("expected index: %d, but got %d", c.expected, idx)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+
+	t.Run("kill", func(t *testing.T) {
+		t.Parallel()
+
+		// The command sleeps for 3 seconds after printing foo. Find must
+		// return soon after enter is pressed.
+		var accepted time.Time
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		go func() {
+			if !term.WaitForLines("> foo") {
+				t.Error("foo must be displayed")
+			}
+			accepted = time.Now()
+			term.SetEventsV2(keys(enter)...)
+		}()
+
+		name, args := helperCommand("sleep", "foo")
+		idx, err := f.Find(nil, nil, fuzzyfinder.WithCommandSource(name, args...))
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		if idx != 0 {
+			t.Errorf("expected index: 0, but got %d", idx)
+		}
+		if d := time.Since(accepted); d > time.Second {
+			t.Errorf("Find must not wait for the command, but it took %s", d)
+		}
+	})
+
+	t.Run("FindMultiCommand", func(t *testing.T) {
+		t.Parallel()
+
+		tab := input{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone}
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		sendSteps(t, term, commandStep{lines: []string{"3/3"}, events: []input{up, up, tab, tab, enter}})
+
+		name, args := helperCommand("print", "foo", "bar", "baz")
+		lines, err := f.FindMultiCommand(name, args)
+		if err != nil {
+			t.Fatalf("FindMultiCommand must not return an error, but got '%s'", err)
+		}
+		if diff := cmp.Diff([]string{"baz", "bar"}, lines); diff != "" {
+			t.Errorf("wrong result: \n%s", diff)
+		}
+	})
+
+	t.Run("nil loader", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := fuzzyfinder.New().Find(
+			[]string{"a"},
+			func(i int) string { return "a" },
+			fuzzyfinder.WithReload("ctrl-r", nil),
+		)
+		if err == nil {
+			t.Error("Find must return an error, but got nil")
+		}
+	})
+}
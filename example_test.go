@@ -69,6 +69,22 @@ func ExampleFindMulti() {
 	}
 }
 
+func ExampleFindT() {
+	type item struct {
+		id   string
+		name string
+	}
+	slice := []item{
+		{"id1", "foo"},
+		{"id2", "bar"},
+		{"id3", "baz"},
+	}
+	it, _ := fuzzyfinder.FindT(slice, func(it item) string {
+		return fmt.Sprintf("[%s] %s", it.id, it.name)
+	})
+	fmt.Println(it) // The selected item.
+}
+
 func ExampleTerminalMock() {
 	// Initialize a mocked terminal.
 	term := fuzzyfinder.UseMockedTerminalV2()
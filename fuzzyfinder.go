@@ -42,9 +42,16 @@ func min(vars ...int) int {
 }
 
 type state struct {
-	items      []string           // All item names.
-	allMatched []matching.Matched // All items.
-	matched    []matching.Matched // Matched items against the input.
+	items itemList // All item names.
+	// rawItems holds the item names including SGR sequences if WithANSI is
+	// passed. items holds the ones without them.
+	rawItems itemList
+	// matched holds the matched items against the input. It is not used if
+	// allMatched is true. Use matchedLen and matchedAt to read them.
+	matched []matching.Matched
+	// allMatched indicates all items are matched in order, such as when the
+	// input is empty. It avoids holding a Matched for each item.
+	allMatched bool
 
 	// x is the current index of the prompt line.
 	x int
@@ -67,6 +74,70 @@ type state struct {
 	selection map[int]int
 	// selectionIdx holds the next index, which is used to a selection's value.
 	selectionIdx int
+
+	// loading indicates items are still being received from a stream.
+	loading bool
+	// version is incremented each time the items are changed.
+	version int
+
+	// keys holds the keys of all items if WithItemKey is passed.
+	keys []string
+	// preselectedKeys holds the keys of the preselected items if WithItemKey
+	// is passed.
+	preselectedKeys map[string]bool
+	// cursorKey is the key of the item which the cursor should be moved to
+	// after the next filtering.
+	cursorKey *string
+
+	// following indicates the cursor follows the newest item. See WithFollow.
+	following bool
+
+	// reloading indicates the loader passed by WithReload is running.
+	reloading bool
+	// reloadGen identifies the latest reload.
+	reloadGen int
+	// errMsg is an error message displayed in the header area.
+	errMsg string
+
+	// tree holds the parent relations of the items if WithTree is passed.
+	tree *tree
+	// treeGuides holds the guide drawn before each displayed item of tree.
+	treeGuides map[int]string
+	// expanded holds the expanded items of tree.
+	expanded map[int]bool
+
+	// levels holds the lists which the user drilled down from by FindNested.
+	levels []level
+	// drilling indicates the children of an item are loaded by FindNested.
+	drilling bool
+
+	// previewScroll is the scroll position of the preview window set by the
+	// user. It is nil unless the preview window is scrolled.
+	previewScroll *previewScroll
+	// previewHidden indicates the preview window is hidden by the user.
+	previewHidden bool
+}
+
+// matchedLen returns the number of the matched items.
+func (s *state) matchedLen() int {
+	if s.allMatched {
+		return s.items.len()
+	}
+	return len(s.matched)
+}
+
+// matchedAt returns the i-th matched item.
+func (s *state) matchedAt(i int) matching.Matched {
+	if s.allMatched {
+		return matching.Matched{Idx: i} //nolint:exhaustivestruct
+	}
+	return s.matched[i]
+}
+
+// matchAll makes all items matched in order.
+func (s *state) matchAll() {
+	s.matched = nil
+	s.allMatched = true
 }
 
 type finder struct {
@@ -78,15 +149,37 @@ type finder struct {
 	opt       *opt
 
 	termEventsChan <-chan tcell.Event
+
+	// cancelReload cancels the running reload. It is guarded by stateMu.
+	cancelReload context.CancelFunc
+	// queryTimer delays calling the function passed by WithQuerySource. It is
+	// guarded by stateMu.
+	queryTimer *time.Timer
+	// commandDone is closed when the command passed by WithCommandSource and
+	// its goroutine finish. It is guarded by stateMu.
+	commandDone chan struct{}
+	// previewer computes previews if WithAsyncPreviewWindow is passed.
+	previewer *previewer
+	// acceptCh receives a value when the item pointed by the cursor is
+	// accepted in the background by FindNested.
+	acceptCh chan struct{}
 }
 
 func newFinder() *finder {
 	return &finder{}
 }
 
-func (f *finder) initFinder(items []string, matched []matching.Matched, opt opt) error {
+func (f *finder) initFinder(items, rawItems itemList, keys []string, opt opt) error {
 	if f.term == nil {
-		screen, err := tcell.NewScreen()
+		var (
+			screen tcell.Screen
+			err    error
+		)
+		if opt.heightSpec != "" {
+			screen, err = newInlineScreen(opt.height)
+		} else {
+			screen, err = tcell.NewScreen()
+		}
 		if err != nil {
 			return errors.Wrap(err, "failed to new screen")
 		}
@@ -96,6 +189,10 @@ func (f *finder) initFinder(items []string, matched []matching.Matched, opt opt)
 		if err := f.term.Init(); err != nil {
 			return errors.Wrap(err, "failed to initialize screen")
 		}
+		if opt.previewFunc != nil && opt.previewMouse {
+			// For scrolling the preview window by the mouse wheel.
+			f.term.EnableMouse(tcell.MouseButtonEvents)
+		}
 
 		eventsChan := make(chan tcell.Event)
 		go f.term.ChannelEvents(eventsChan, nil)
@@ -103,7 +200,17 @@ func (f *finder) initFinder(items []string, matched []matching.Matched, opt opt)
 	}
 
 	f.opt = &opt
-	f.state = state{}
+	f.state = state{items: items, rawItems: rawItems, allMatched: true, previewHidden: opt.previewHidden}
+
+	if keys != nil {
+		f.state.keys = keys
+		f.state.preselectedKeys = map[string]bool{}
+		for i, k := range keys {
+			if opt.preselected(i) {
+				f.state.preselectedKeys[k] = true
+			}
+		}
+	}
 
 	var cursorPositioned bool
 	if opt.multi {
@@ -111,38 +218,36 @@ func (f *finder) initFinder(items []string, matched []matching.Matched, opt opt)
 		f.state.selectionIdx = 1
 
 		// Apply preselection
-		for i := range items {
-			if opt.preselected(i) {
+		for i := 0; i < items.len(); i++ {
+			if f.isPreselected(i) {
 				f.state.selection[i] = f.state.selectionIdx
 				f.state.selectionIdx++
 			}
 		}
 	} else {
 		// In non-multi mode, set the cursor position to the first preselected item
-		for i := range items {
-			if opt.preselected(i) {
+		for i := 0; i < items.len(); i++ {
+			if f.isPreselected(i) {
 				cursorPositioned = true
-				// Find the matched item index
-				for j, m := range matched {
-					if m.Idx == i {
-						f.state.y = j
-						f.state.cursorY = min(j, len(matched)-1)
-						break
-					}
-				}
+				// All items are matched in order, so the matched item index
+				// is the same as i.
+				f.state.y = i
+				f.state.cursorY = i
 				break // Only use the first preselected item
 			}
 		}
 	}
 
-	f.state.items = items
-	f.state.matched = matched
-	f.state.allMatched = matched
-
 	// If no preselected item is found and beginAtTop is true, set the cursor to the last item
-	if !cursorPositioned && opt.beginAtTop {
-		f.state.cursorY = len(f.state.matched) - 1
-		f.state.y = len(f.state.matched) - 1
+	if !cursorPositioned && opt.beginAtTop && opt.layout == LayoutDefault {
+		f.state.cursorY = f.state.matchedLen() - 1
+		f.state.y = f.state.matchedLen() - 1
+	}
+	f.fitCursor()
+
+	if opt.follow {
+		f.state.following = true
+		f.moveCursorToNewest()
 	}
 
 	if !isInTesting() {
@@ -156,49 +261,411 @@ func (f *finder) initFinder(items []string, matched []matching.Matched, opt opt)
 		f.drawTimer.Stop()
 	}
 	f.eventCh = make(chan struct{}, 30) // A large value
+	f.acceptCh = make(chan struct{}, 1)
 
+	if opt.treeParent != nil {
+		f.state.expanded = map[int]bool{}
+	}
 	if opt.query != "" {
 		f.state.input = []rune(opt.query)
 		f.state.cursorX = runewidth.StringWidth(opt.query)
 		f.state.x = len(opt.query)
 		f.filter()
+	} else if opt.treeParent != nil {
+		f.filter()
 	}
 
 	return nil
 }
 
-func (f *finder) updateItems(items []string, matched []matching.Matched) {
+// applyChanges applies changes received from an ItemSource to the state.
+// Appended items are matched against the current input and merged into the
+// matched items. For other changes, the matched items are adjusted so that
+// they can be drawn immediately, and then they are filtered again by the
+// event loop.
+//
+// If WithItemKey is passed, the selections and the cursor follow the keys of
+// the items instead of their indices.
+func (f *finder) applyChanges(changes []ItemChange) {
 	f.stateMu.Lock()
-	f.state.items = items
-	f.state.matched = matched
-	f.state.allMatched = matched
-
-	// Apply preselection to any new items
-	if f.opt.multi {
-		for i := 0; i < len(items); i++ {
-			// Check if this item is not already in the selection and should be preselected
-			if _, exists := f.state.selection[i]; !exists && f.opt.preselected(i) {
-				f.state.selection[i] = f.state.selectionIdx
-				f.state.selectionIdx++
+
+	var (
+		cursorKey    *string
+		selectedKeys map[string]int
+		// cursorIdx is the index of the item pointed by the cursor.
+		cursorIdx   = -1
+		needsFilter bool
+	)
+	if f.state.matchedLen() > 0 {
+		cursorIdx = f.state.matchedAt(f.state.y).Idx
+	}
+	if f.state.keys != nil {
+		if f.state.matchedLen() > 0 {
+			k := f.state.keys[cursorIdx]
+			cursorKey = &k
+		}
+		selectedKeys = make(map[string]int, len(f.state.selection))
+		for idx, pos := range f.state.selection {
+			selectedKeys[f.state.keys[idx]] = pos
+		}
+	}
+
+	for _, c := range changes {
+		// raw holds the items including SGR sequences if WithANSI is passed.
+		var raw []string
+		if f.opt.ansi {
+			raw = c.Items
+			c.Items = stripANSIAll(c.Items)
+		}
+
+		n := f.state.items.len()
+		switch c.Kind {
+		case ItemsAppended:
+			f.state.items.append(c.Items...)
+			f.state.rawItems.append(raw...)
+			if f.state.keys != nil {
+				f.state.keys = append(f.state.keys, f.changedKeys(c, n)...)
+			}
+			if f.opt.group != nil {
+				// The matched items are sorted by groups, so they can't be
+				// merged by scores.
+				needsFilter = true
+			} else if !f.state.allMatched && len(f.state.input) > 0 {
+				matched := matching.FindAll(string(f.state.input), c.Items, matching.WithMode(matching.Mode(f.opt.mode)))
+				for i := range matched {
+					matched[i].Idx += n
+				}
+				f.state.matched = matching.Merge(f.state.matched, matched)
+			}
+			for i := n; i < f.state.items.len(); i++ {
+				if f.state.keys != nil && f.opt.preselected(i) {
+					f.state.preselectedKeys[f.state.keys[i]] = true
+				}
+				// Apply preselection to new items.
+				if f.opt.multi && f.isPreselected(i) {
+					f.state.selection[i] = f.state.selectionIdx
+					f.state.selectionIdx++
+				}
+			}
+		case ItemsRemoved:
+			if c.Index < 0 || c.Count <= 0 || c.Index+c.Count > n {
+				continue
+			}
+			needsFilter = true
+			from, to := c.Index, c.Index+c.Count
+			f.state.items.remove(from, to)
+			if f.opt.ansi {
+				f.state.rawItems.remove(from, to)
+			}
+			if f.state.keys != nil {
+				f.state.keys = append(f.state.keys[:from:from], f.state.keys[to:]...)
+			}
+			if !f.state.allMatched {
+				f.state.matched = removeMatched(f.state.matched, from, to)
+			}
+			if f.opt.multi {
+				selection := make(map[int]int, len(f.state.selection))
+				for idx, pos := range f.state.selection {
+					switch {
+					case idx < from:
+						selection[idx] = pos
+					case idx >= to:
+						selection[idx-c.Count] = pos
+					}
+				}
+				f.state.selection = selection
+			}
+		case itemsReset:
+			needsFilter = true
+			f.state.items = newItemList(c.Items)
+			f.state.rawItems = newItemList(raw)
+			if f.state.keys != nil {
+				f.state.keys = f.changedKeys(c, 0)
+			}
+			f.state.matched = nil
+			if f.opt.multi {
+				// The selections are restored by the keys.
+				f.state.selection = map[int]int{}
+			}
+		case ItemsUpdated:
+			if c.Index < 0 || c.Index+len(c.Items) > n {
+				continue
+			}
+			needsFilter = true
+			f.state.items.update(c.Index, c.Items)
+			if f.opt.ansi {
+				f.state.rawItems.update(c.Index, raw)
+			}
+			if f.state.keys != nil {
+				copy(f.state.keys[c.Index:], f.changedKeys(c, c.Index))
+			}
+		}
+	}
+
+	if needsFilter && selectedKeys != nil && f.opt.multi {
+		selection := make(map[int]int, len(selectedKeys))
+		for i, k := range f.state.keys {
+			if pos, ok := selectedKeys[k]; ok {
+				selection[i] = pos
+				// Only the first item is selected if keys are duplicated.
+				delete(selectedKeys, k)
+			}
+		}
+		f.state.selection = selection
+	}
+	if f.opt.treeParent != nil {
+		// The rows of the tree are built by filtering.
+		needsFilter = true
+	} else if len(f.state.input) == 0 || !f.filtersLocally() {
+		f.state.matchAll()
+	}
+	f.state.version++
+	f.clampCursor()
+
+	if !needsFilter {
+		// The indices of the existing items are not changed, so the cursor
+		// is kept on the same item.
+		if f.state.following {
+			f.moveCursorToNewest()
+			f.stateMu.Unlock()
+			f.draw(0)
+			return
+		}
+		for i := 0; i < f.state.matchedLen(); i++ {
+			if f.state.matchedAt(i).Idx == cursorIdx {
+				f.moveCursorTo(i)
+				break
+			}
+		}
+		f.stateMu.Unlock()
+		f.draw(0)
+		return
+	}
+
+	if cursorKey != nil {
+		f.state.cursorKey = cursorKey
+		f.moveCursorToKey()
+	}
+	f.stateMu.Unlock()
+
+	f.requestFilter()
+}
+
+// requestFilter requests the event loop to filter the items and draw them.
+func (f *finder) requestFilter() {
+	// If the buffer is full, filtering is already requested.
+	select {
+	case f.eventCh <- struct{}{}:
+	default:
+	}
+}
+
+// changedKeys returns the keys of the items of c. from is the index of the
+// first item.
+func (f *finder) changedKeys(c ItemChange, from int) []string {
+	if c.keys != nil {
+		return c.keys
+	}
+	if f.opt.itemKey == nil {
+		// The items themselves are used as the keys.
+		return c.Items
+	}
+	keys := make([]string, len(c.Items))
+	for i := range c.Items {
+		keys[i] = f.opt.itemKey(from + i)
+	}
+	return keys
+}
+
+// isPreselected reports whether the item is preselected. If WithItemKey is
+// passed, it is determined by the key of the item.
+func (f *finder) isPreselected(idx int) bool {
+	if f.state.keys != nil {
+		return f.state.preselectedKeys[f.state.keys[idx]]
+	}
+	return f.opt.preselected(idx)
+}
+
+// followCursorKey moves the cursor to the item which has cursorKey after
+// filtering, and then clears cursorKey. It reports whether the item is found.
+func (f *finder) followCursorKey() bool {
+	if f.state.cursorKey == nil {
+		return false
+	}
+	found := f.moveCursorToKey()
+	f.state.cursorKey = nil
+	return found
+}
+
+// moveCursorToKey moves the cursor to the matched item which has cursorKey.
+// It reports whether the item is found.
+func (f *finder) moveCursorToKey() bool {
+	if f.state.cursorKey == nil {
+		return false
+	}
+	for i := 0; i < f.state.matchedLen(); i++ {
+		if f.state.keys[f.state.matchedAt(i).Idx] == *f.state.cursorKey {
+			f.moveCursorTo(i)
+			return true
+		}
+	}
+	return false
+}
+
+// moveCursorToNewest moves the cursor to the matched item which has the
+// largest index.
+func (f *finder) moveCursorToNewest() {
+	if f.state.allMatched {
+		if n := f.state.matchedLen(); n > 0 {
+			f.moveCursorTo(n - 1)
+		}
+		return
+	}
+	newest := -1
+	for i, m := range f.state.matched {
+		if newest == -1 || m.Idx > f.state.matched[newest].Idx {
+			newest = i
+		}
+	}
+	if newest != -1 {
+		f.moveCursorTo(newest)
+	}
+}
+
+// cursorToNext moves the cursor to the next matched item, which is displayed
+// above the current one in LayoutDefault.
+func (f *finder) cursorToNext(screenHeight int) {
+	n := f.state.matchedLen()
+	if f.state.y+1 < n {
+		f.state.y++
+	}
+	if f.state.cursorY+1 < min(n, screenHeight-2) {
+		f.state.cursorY++
+	}
+	f.fitCursor()
+}
+
+// cursorToPrev moves the cursor to the previous matched item, which is
+// displayed below the current one in LayoutDefault.
+func (f *finder) cursorToPrev() {
+	if f.state.y > 0 {
+		f.state.y--
+	}
+	if f.state.cursorY > 0 {
+		f.state.cursorY--
+	}
+}
+
+// moveCursorTo moves the cursor to the i-th matched item. The scroll position
+// is kept as much as possible.
+func (f *finder) moveCursorTo(i int) {
+	_, height := f.listPane().Size()
+	f.state.cursorY = min(max(f.state.cursorY+i-f.state.y, 0), height-3, i)
+	f.state.y = i
+	f.fitCursor()
+}
+
+// removeMatched removes matched items whose index is in [from, to) and shifts
+// the indices of the following items. It returns a new slice.
+func removeMatched(matched []matching.Matched, from, to int) []matching.Matched {
+	res := make([]matching.Matched, 0, len(matched))
+	for _, m := range matched {
+		switch {
+		case m.Idx < from:
+			res = append(res, m)
+		case m.Idx >= to:
+			m.Idx -= to - from
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// clampCursor keeps the cursor within the matched items.
+func (f *finder) clampCursor() {
+	n := f.state.matchedLen()
+	if n == 0 {
+		f.state.cursorY = 0
+		f.state.y = 0
+		return
+	}
+	f.state.y = max(0, min(f.state.y, n-1))
+	f.state.cursorY = max(0, min(f.state.cursorY, f.state.y))
+}
+
+// receiveChanges receives changes from the passed channel and applies them.
+// Changes which are already queued are applied at once.
+func (f *finder) receiveChanges(ctx context.Context, changes <-chan ItemChange) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c, ok := <-changes:
+			if !ok {
+				return
+			}
+			batch := []ItemChange{c}
+		DRAIN:
+			for {
+				select {
+				case c, ok := <-changes:
+					if !ok {
+						break DRAIN
+					}
+					batch = append(batch, c)
+				default:
+					break DRAIN
+				}
 			}
+			f.applyChanges(batch)
 		}
 	}
+}
+
+// watchLoading updates the loading state until loading returns false.
+func (f *finder) watchLoading(ctx context.Context, loading func() bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+			l := loading()
+			f.updateLoading(l)
+			if !l {
+				return
+			}
+		}
+	}
+}
 
+// updateLoading updates the loading state. While loading, the number line is
+// redrawn to animate the spinner.
+func (f *finder) updateLoading(loading bool) {
+	f.stateMu.Lock()
+	changed := f.state.loading != loading
+	f.state.loading = loading
 	f.stateMu.Unlock()
-	f.eventCh <- struct{}{}
+
+	if changed || loading {
+		f.draw(0)
+	}
 }
 
 // _draw is used from draw with a timer.
 func (f *finder) _draw() {
-	width, height := f.term.Size()
 	f.term.Clear()
 
-	maxWidth := width
-	if f.opt.previewFunc != nil {
-		maxWidth = width/2 - 1
-	}
+	term := f.listPane()
+	maxWidth, height := term.Size()
 
 	maxHeight := height
+	// lineRow returns the row of the next line from the prompt line.
+	lineRow := func() int {
+		if f.promptOnTop() {
+			return height - maxHeight
+		}
+		return maxHeight - 1
+	}
 
 	// prompt line
 	var promptLinePad int
@@ -208,7 +675,7 @@ func (f *finder) _draw() {
 			Foreground(tcell.ColorBlue).
 			Background(tcell.ColorDefault)
 
-		f.term.SetContent(promptLinePad, maxHeight-1, r, nil, style)
+		term.SetContent(promptLinePad, lineRow(), r, nil, style)
 		promptLinePad++
 	}
 	var r rune
@@ -220,135 +687,297 @@ func (f *finder) _draw() {
 			Bold(true)
 
 		// Add a space between '>' and runes.
-		f.term.SetContent(promptLinePad+w, maxHeight-1, r, nil, style)
+		term.SetContent(promptLinePad+w, lineRow(), r, nil, style)
 		w += runewidth.RuneWidth(r)
 	}
-	f.term.ShowCursor(promptLinePad+f.state.cursorX, maxHeight-1)
+	term.ShowCursor(promptLinePad+f.state.cursorX, lineRow())
 
 	maxHeight--
 
 	// Header line
-	if len(f.opt.header) > 0 {
+	if header := f.headerLine(); len(header) > 0 {
 		w = 0
-		for _, r := range runewidth.Truncate(f.opt.header, maxWidth-2, "..") {
+		for _, r := range runewidth.Truncate(header, maxWidth-2, "..") {
 			style := tcell.StyleDefault.
 				Foreground(tcell.ColorGreen).
 				Background(tcell.ColorDefault)
-			f.term.SetContent(2+w, maxHeight-1, r, nil, style)
+			term.SetContent(2+w, lineRow(), r, nil, style)
+			w += runewidth.RuneWidth(r)
+		}
+		maxHeight--
+	}
+
+	// Error line
+	if f.state.errMsg != "" {
+		w = 0
+		msg := strings.ReplaceAll(f.state.errMsg, "\n", " ")
+		for _, r := range runewidth.Truncate(msg, maxWidth-2, "..") {
+			style := tcell.StyleDefault.
+				Foreground(tcell.ColorRed).
+				Background(tcell.ColorDefault)
+			term.SetContent(2+w, lineRow(), r, nil, style)
 			w += runewidth.RuneWidth(r)
 		}
 		maxHeight--
 	}
 
 	// Number line
-	for i, r := range fmt.Sprintf("%d/%d", len(f.state.matched), len(f.state.items)) {
+	numberLine := fmt.Sprintf("%d/%d", f.state.matchedLen(), f.state.items.len())
+	if f.state.loading || f.state.reloading {
+		numberLine = fmt.Sprintf("%c %s", spinnerFrame(), numberLine)
+	}
+	if f.opt.follow {
+		if f.state.following {
+			numberLine += " [follow]"
+		} else {
+			numberLine += " [paused]"
+		}
+	}
+	for i, r := range []rune(numberLine) {
 		style := tcell.StyleDefault.
 			Foreground(tcell.ColorYellow).
 			Background(tcell.ColorDefault)
 
-		f.term.SetContent(2+i, maxHeight-1, r, nil, style)
+		term.SetContent(2+i, lineRow(), r, nil, style)
 	}
 	maxHeight--
 
+	// Column header line
+	var widths []int
+	if f.opt.tableColumns != nil {
+		widths = f.columnWidths(maxHeight-1, maxWidth-2)
+		f.drawColumnHeader(widths, lineRow(), maxWidth)
+		maxHeight--
+	}
+
 	// Item lines
-	itemAreaHeight := maxHeight - 1
-	matched := f.state.matched
+	// The item lines are drawn in [areaTop, areaBottom].
+	areaTop, areaBottom := 0, maxHeight-1
+	if f.promptOnTop() {
+		areaTop, areaBottom = height-maxHeight, height-1
+	}
+	visible := func(row int) bool {
+		return areaTop <= row && row <= areaBottom
+	}
+	topDown := f.itemsTopDown()
+	// next is the row next to the previous item in the direction in which
+	// the items are drawn.
+	next := areaBottom
+	if topDown {
+		next = areaTop
+	}
 	offset := f.state.cursorY
 	y := f.state.y
-	// From the first (the most bottom) item in the item lines to the end.
-	matched = matched[y-offset:]
-
-	for i, m := range matched {
-		if i > itemAreaHeight {
-			break
+	// From the first item in the item lines, which is the nearest one to
+	// where the items are drawn from, to the end.
+	first := y - offset
+
+	for i := 0; first+i < f.state.matchedLen() && visible(next); i++ {
+		m := f.state.matchedAt(first + i)
+		// The item is drawn in [top, top+h). Rows outside of the item lines
+		// are clipped.
+		h := f.matchedHeight(first+i, maxHeight)
+		var top int
+		if topDown {
+			top = next
+			next = top + h
+		} else {
+			top = next - h + 1
+			next = top - 1
+		}
+		if name, ok := f.groupHeader(first + i); ok {
+			if visible(top) {
+				f.drawGroupHeader(name, top, maxWidth)
+			}
+			top++
+			h--
 		}
-		if i == f.state.cursorY {
+
+		if i == f.state.cursorY && visible(top) {
 			style := tcell.StyleDefault.
 				Foreground(tcell.ColorRed).
 				Background(tcell.ColorBlack)
 
-			f.term.SetContent(0, maxHeight-1-i, '>', nil, style)
-			f.term.SetContent(1, maxHeight-1-i, ' ', nil, style)
+			term.SetContent(0, top, '>', nil, style)
+			term.SetContent(1, top, ' ', nil, style)
 		}
 
-		if f.opt.multi {
+		if f.opt.multi && visible(top) {
 			if _, ok := f.state.selection[m.Idx]; ok {
 				style := tcell.StyleDefault.
 					Foreground(tcell.ColorRed).
 					Background(tcell.ColorBlack)
 
-				f.term.SetContent(1, maxHeight-1-i, '>', nil, style)
+				term.SetContent(1, top, '>', nil, style)
 			}
 		}
 
-		var posIdx int
+		hl := f.newHighlighter(m)
+		var line int
 		w := 2
-		for j, r := range []rune(f.state.items[m.Idx]) {
-			style := tcell.StyleDefault.
-				Foreground(tcell.ColorDefault).
-				Background(tcell.ColorDefault)
-			// Highlight selected strings.
-			hasHighlighted := false
-			if posIdx < len(f.state.input) {
-				from, to := m.Pos[0], m.Pos[1]
-				if !(from == -1 && to == -1) && (from <= j && j <= to) {
-					if unicode.ToLower(f.state.input[posIdx]) == unicode.ToLower(r) {
-						style = tcell.StyleDefault.
-							Foreground(tcell.ColorGreen).
-							Background(tcell.ColorDefault)
-						hasHighlighted = true
-						posIdx++
+		// shortened indicates the rest of the current line is omitted.
+		var shortened bool
+		itemStyle := tcell.StyleDefault
+		if f.opt.itemStyle != nil {
+			itemStyle = f.opt.itemStyle(m.Idx).tcell()
+		}
+		// textWidth is the width for the first line, which may have an
+		// annotation.
+		textWidth := maxWidth
+		if f.opt.annotation != nil && visible(top) {
+			textWidth = f.drawAnnotation(m.Idx, top, maxWidth, i == f.state.cursorY)
+		}
+
+		if f.state.tree != nil && visible(top) {
+			w = f.drawTreeGuide(m.Idx, w, top, textWidth, i == f.state.cursorY)
+		}
+
+		if widths != nil {
+			if visible(top) {
+				f.drawTableRow(m, widths, top, textWidth, itemStyle, i == f.state.cursorY)
+			}
+			continue
+		}
+
+		runes, styles := f.itemRunes(m.Idx)
+		for j, r := range runes {
+			if f.opt.multiLine && r == '\n' {
+				line++
+				if line == h {
+					// Indicate the omitted lines.
+					if visible(top+h-1) && !shortened && w+1+2 <= maxWidth {
+						style := itemStyle
+						if i == f.state.cursorY {
+							style = style.Foreground(tcell.ColorYellow).Bold(true).Background(tcell.ColorBlack)
+						}
+						term.SetContent(w, top+h-1, '…', nil, style)
 					}
+					break
 				}
+				w = 2
+				shortened = false
+				continue
 			}
-			if i == f.state.cursorY {
-				if hasHighlighted {
-					style = tcell.StyleDefault.
-						Foreground(tcell.ColorDarkCyan).
-						Bold(true).
-						Background(tcell.ColorBlack)
-				} else {
-					style = tcell.StyleDefault.
-						Foreground(tcell.ColorYellow).
-						Bold(true).
-						Background(tcell.ColorBlack)
-				}
+
+			style := itemStyle
+			if styles != nil {
+				style = overlayStyle(style, styles[j])
 			}
+			// Highlight selected strings.
+			style = itemRuneStyle(style, hl.match(j, r), i == f.state.cursorY)
 
+			if shortened || !visible(top+line) {
+				// Keep reading runes to highlight the following lines.
+				continue
+			}
+
+			lineWidth := maxWidth
+			if line == 0 {
+				lineWidth = textWidth
+			}
 			rw := runewidth.RuneWidth(r)
 			// Shorten item cells.
-			if w+rw+2 > maxWidth {
-				f.term.SetContent(w, maxHeight-1-i, '.', nil, style)
-				f.term.SetContent(w+1, maxHeight-1-i, '.', nil, style)
-				break
+			if w+rw+2 > lineWidth {
+				term.SetContent(w, top+line, '.', nil, style)
+				term.SetContent(w+1, top+line, '.', nil, style)
+				shortened = true
 			} else {
-				f.term.SetContent(w, maxHeight-1-i, r, nil, style)
+				term.SetContent(w, top+line, r, nil, style)
 				w += rw
 			}
 		}
 	}
 }
 
+// itemRunes returns the runes of the item to draw. If WithANSI is passed, it
+// also returns the styles of the runes parsed from SGR sequences. Otherwise,
+// the styles are nil.
+func (f *finder) itemRunes(idx int) ([]rune, []tcell.Style) {
+	if f.opt.ansi {
+		return ansiRunes(f.state.rawItems.at(idx))
+	}
+	return []rune(f.state.items.at(idx)), nil
+}
+
+// highlighter finds the runes of an item which are matched against the input.
+type highlighter struct {
+	input  []rune
+	pos    [2]int
+	posIdx int
+}
+
+// newHighlighter returns a highlighter for m. It highlights nothing if the
+// items are not filtered by the input.
+func (f *finder) newHighlighter(m matching.Matched) *highlighter {
+	h := &highlighter{input: f.state.input, pos: m.Pos}
+	if !f.filtersLocally() {
+		h.input = nil
+	}
+	return h
+}
+
+// match reports whether the j-th rune r of the item is highlighted. It must
+// be called for the runes in order.
+func (h *highlighter) match(j int, r rune) bool {
+	if h.posIdx >= len(h.input) {
+		return false
+	}
+	from, to := h.pos[0], h.pos[1]
+	if (from == -1 && to == -1) || j < from || to < j {
+		return false
+	}
+	if unicode.ToLower(h.input[h.posIdx]) != unicode.ToLower(r) {
+		return false
+	}
+	h.posIdx++
+	return true
+}
+
+// itemRuneStyle returns style with the highlight and the cursor line style on
+// top of it.
+func itemRuneStyle(style tcell.Style, highlighted, cursor bool) tcell.Style {
+	if highlighted {
+		style = style.Foreground(tcell.ColorGreen)
+	}
+	if cursor {
+		fg, _, _ := style.Decompose()
+		switch {
+		case highlighted:
+			style = style.Foreground(tcell.ColorDarkCyan)
+		case fg == tcell.ColorDefault:
+			style = style.Foreground(tcell.ColorYellow)
+		}
+		style = style.Bold(true).Background(tcell.ColorBlack)
+	}
+	return style
+}
+
 func (f *finder) _drawPreview() {
 	if f.opt.previewFunc == nil {
 		return
 	}
 
-	width, height := f.term.Size()
-	var idx int
-	if len(f.state.matched) == 0 {
-		idx = -1
-	} else {
-		idx = f.state.matched[f.state.y].Idx
+	term := f.previewPane()
+	width, height := term.Size()
+	if width == 0 {
+		// The preview window is hidden.
+		return
 	}
 
-	iter := ansisgr.NewIterator(f.opt.previewFunc(idx, width, height))
+	idx := f.previewIdx()
+	res := f.preview(idx)
+	iter := ansisgr.NewIterator(res.Text)
+	// Skip the lines above the preview window.
+	offset := f.previewOffset(idx, res, height-2)
+	for i := 0; i < offset; i++ {
+		consumeIterator(iter, '\n')
+	}
 
 	// top line
-	for i := width / 2; i < width; i++ {
+	for i := 0; i < width; i++ {
 		var r rune
 		switch {
-		case i == width/2:
+		case i == 0:
 			r = '┌'
 		case i == width-1:
 			r = '┐'
@@ -360,13 +989,25 @@ func (f *finder) _drawPreview() {
 			Foreground(tcell.ColorBlack).
 			Background(tcell.ColorDefault)
 
-		f.term.SetContent(i, 0, r, nil, style)
+		term.SetContent(i, 0, r, nil, style)
+	}
+	// The scroll position on the top line if the preview doesn't fit.
+	if lines := strings.Count(res.Text, "\n") + 1; lines > height-2 {
+		pos := fmt.Sprintf(" %d/%d ", offset+1, lines)
+		if len(pos)+2 <= width {
+			style := tcell.StyleDefault.
+				Foreground(tcell.ColorYellow).
+				Background(tcell.ColorDefault)
+			for i, r := range pos {
+				term.SetContent(width-1-len(pos)+i, 0, r, nil, style)
+			}
+		}
 	}
 	// bottom line
-	for i := width / 2; i < width; i++ {
+	for i := 0; i < width; i++ {
 		var r rune
 		switch {
-		case i == width/2:
+		case i == 0:
 			r = '└'
 		case i == width-1:
 			r = '┘'
@@ -378,7 +1019,7 @@ func (f *finder) _drawPreview() {
 			Foreground(tcell.ColorBlack).
 			Background(tcell.ColorDefault)
 
-		f.term.SetContent(i, height-1, r, nil, style)
+		term.SetContent(i, height-1, r, nil, style)
 	}
 	// Start with h=1 to exclude each corner rune.
 	const vline = '│'
@@ -386,30 +1027,30 @@ func (f *finder) _drawPreview() {
 	for h := 1; h < height-1; h++ {
 		// donePreviewLine indicates the preview string of the current line identified by h is already drawn.
 		var donePreviewLine bool
-		w := width / 2
-		for i := width / 2; i < width; i++ {
+		w := 0
+		for i := 0; i < width; i++ {
 			switch {
 			// Left vertical line.
-			case i == width/2:
+			case i == 0:
 				style := tcell.StyleDefault.
 					Foreground(tcell.ColorBlack).
 					Background(tcell.ColorDefault)
-				f.term.SetContent(i, h, vline, nil, style)
+				term.SetContent(i, h, vline, nil, style)
 				w += wvline
 			// Right vertical line.
 			case i == width-1:
 				style := tcell.StyleDefault.
 					Foreground(tcell.ColorBlack).
 					Background(tcell.ColorDefault)
-				f.term.SetContent(i, h, vline, nil, style)
+				term.SetContent(i, h, vline, nil, style)
 				w += wvline
 			// Spaces between left and right vertical lines.
-			case w == width/2+wvline, w == width-1-wvline:
+			case w == wvline, w == width-1-wvline:
 				style := tcell.StyleDefault.
 					Foreground(tcell.ColorDefault).
 					Background(tcell.ColorDefault)
 
-				f.term.SetContent(w, h, ' ', nil, style)
+				term.SetContent(w, h, ' ', nil, style)
 				w++
 			default: // Preview text
 				if donePreviewLine {
@@ -434,46 +1075,15 @@ func (f *finder) _drawPreview() {
 						Foreground(tcell.ColorDefault).
 						Background(tcell.ColorDefault)
 
-					f.term.SetContent(w, h, '.', nil, style)
-					f.term.SetContent(w+1, h, '.', nil, style)
+					term.SetContent(w, h, '.', nil, style)
+					term.SetContent(w+1, h, '.', nil, style)
 
 					w += 2
 					continue
 				}
 
-				style := tcell.StyleDefault
-				if color, ok := rstyle.Foreground(); ok {
-					switch color.Mode() {
-					case ansisgr.Mode16:
-						style = style.Foreground(tcell.PaletteColor(color.Value() - 30))
-					case ansisgr.Mode256:
-						style = style.Foreground(tcell.PaletteColor(color.Value()))
-					case ansisgr.ModeRGB:
-						r, g, b := color.RGB()
-						style = style.Foreground(tcell.NewRGBColor(int32(r), int32(g), int32(b)))
-					}
-				}
-				if color, valid := rstyle.Background(); valid {
-					switch color.Mode() {
-					case ansisgr.Mode16:
-						style = style.Background(tcell.PaletteColor(color.Value() - 40))
-					case ansisgr.Mode256:
-						style = style.Background(tcell.PaletteColor(color.Value()))
-					case ansisgr.ModeRGB:
-						r, g, b := color.RGB()
-						style = style.Background(tcell.NewRGBColor(int32(r), int32(g), int32(b)))
-					}
-				}
-
-				style = style.
-					Bold(rstyle.Bold()).
-					Dim(rstyle.Dim()).
-					Italic(rstyle.Italic()).
-					Underline(rstyle.Underline()).
-					Blink(rstyle.Blink()).
-					Reverse(rstyle.Reverse()).
-					StrikeThrough(rstyle.Strikethrough())
-				f.term.SetContent(w, h, r, nil, style)
+				style := sgrStyle(rstyle)
+				term.SetContent(w, h, r, nil, style)
 				w += rw
 			}
 		}
@@ -501,11 +1111,15 @@ func (f *finder) draw(d time.Duration) {
 func (f *finder) readKey(ctx context.Context) error {
 	f.stateMu.RLock()
 	prevInputLen := len(f.state.input)
+	prevInput := string(f.state.input)
 	f.stateMu.RUnlock()
 	defer func() {
-		f.stateMu.RLock()
+		f.stateMu.Lock()
 		currentInputLen := len(f.state.input)
-		f.stateMu.RUnlock()
+		if f.opt.querySource != nil && prevInput != string(f.state.input) {
+			f.queryChanged(ctx)
+		}
+		f.stateMu.Unlock()
 		if prevInputLen != currentInputLen {
 			f.eventCh <- struct{}{}
 		}
@@ -516,6 +1130,8 @@ func (f *finder) readKey(ctx context.Context) error {
 	select {
 	case ee := <-f.termEventsChan:
 		e = ee
+	case <-f.acceptCh:
+		return errEntered
 	case <-ctx.Done():
 		return ctx.Err()
 	}
@@ -523,19 +1139,41 @@ func (f *finder) readKey(ctx context.Context) error {
 	f.stateMu.Lock()
 	defer f.stateMu.Unlock()
 
-	_, screenHeight := f.term.Size()
-	matchedLinesCount := len(f.state.matched)
+	f.resetPreviewScroll()
+
+	_, screenHeight := f.listPane().Size()
+	matchedLinesCount := f.state.matchedLen()
 
 	// Max number of lines to scroll by using PgUp and PgDn
 	var pageScrollBy = screenHeight - 3
 
 	switch e := e.(type) {
 	case *tcell.EventKey:
-		switch e.Key() {
+		if f.drillingKey(e) {
+			return nil
+		}
+		if f.opt.reloadKeyName != "" && f.opt.reloadKey.match(e) {
+			if f.opt.reloader != nil {
+				f.reload(ctx, f.opt.reloader)
+			} else {
+				f.runCommand(ctx)
+			}
+			return nil
+		}
+		if f.opt.previewToggleKeyName != "" && f.opt.previewToggleKey.match(e) {
+			f.togglePreview()
+			return nil
+		}
+		if f.treeKey(e) || f.previewKey(e) {
+			return nil
+		}
+
+		switch f.layoutKey(e.Key()) {
 		case tcell.KeyEsc, tcell.KeyCtrlC, tcell.KeyCtrlD:
 			return ErrAbort
 		case tcell.KeyBackspace, tcell.KeyBackspace2:
 			if len(f.state.input) == 0 {
+				f.drillUp()
 				return nil
 			}
 			if f.state.x == 0 {
@@ -553,6 +1191,9 @@ func (f *finder) readKey(ctx context.Context) error {
 
 			f.state.input = append(f.state.input[:x], f.state.input[x+1:]...)
 		case tcell.KeyEnter:
+			if f.toggleTreeNode() || f.drillDown(ctx) {
+				return nil
+			}
 			return errEntered
 		case tcell.KeyLeft, tcell.KeyCtrlB:
 			if f.state.x > 0 {
@@ -590,46 +1231,56 @@ func (f *finder) readKey(ctx context.Context) error {
 			f.state.cursorX = 0
 			f.state.x = 0
 		case tcell.KeyUp, tcell.KeyCtrlK, tcell.KeyCtrlP:
-			if f.state.y+1 < matchedLinesCount {
-				f.state.y++
-			}
-			if f.state.cursorY+1 < min(matchedLinesCount, screenHeight-2) {
-				f.state.cursorY++
-			}
+			f.state.following = false
+			f.cursorToNext(screenHeight)
 		case tcell.KeyDown, tcell.KeyCtrlJ, tcell.KeyCtrlN:
-			if f.state.y > 0 {
-				f.state.y--
-			}
-			if f.state.cursorY-1 >= 0 {
-				f.state.cursorY--
-			}
+			f.state.following = false
+			f.cursorToPrev()
 		case tcell.KeyPgUp:
-			f.state.y += min(pageScrollBy, matchedLinesCount-1-f.state.y)
+			f.state.following = false
+			by := f.pageItems(true, pageScrollBy)
+			// Without matched items, the bounds are -1, so y and cursorY
+			// are kept at 0.
+			f.state.y = max(0, f.state.y+min(by, matchedLinesCount-1-f.state.y))
 			maxCursorY := min(screenHeight-3, matchedLinesCount-1)
-			f.state.cursorY += min(pageScrollBy, maxCursorY-f.state.cursorY)
+			f.state.cursorY = max(0, f.state.cursorY+min(by, maxCursorY-f.state.cursorY))
+			f.fitCursor()
 		case tcell.KeyPgDn:
-			f.state.y -= min(pageScrollBy, f.state.y)
-			f.state.cursorY -= min(pageScrollBy, f.state.cursorY)
+			f.state.following = false
+			by := f.pageItems(false, pageScrollBy)
+			f.state.y = max(0, f.state.y-min(by, f.state.y))
+			f.state.cursorY = max(0, f.state.cursorY-min(by, f.state.cursorY))
 		case tcell.KeyTab:
-			if !f.opt.multi {
+			if !f.opt.multi || f.state.matchedLen() == 0 {
 				return nil
 			}
-			idx := f.state.matched[f.state.y].Idx
-			if _, ok := f.state.selection[idx]; ok {
+			idx := f.state.matchedAt(f.state.y).Idx
+			_, selected := f.state.selection[idx]
+			switch {
+			case f.selectTreeLeaves():
+				// The leaves under the item are toggled instead.
+			case selected:
 				delete(f.state.selection, idx)
-			} else {
+			default:
 				f.state.selection[idx] = f.state.selectionIdx
 				f.state.selectionIdx++
 			}
-			if f.state.y > 0 {
-				f.state.y--
+			f.state.following = false
+			// Move the cursor down.
+			if f.itemsTopDown() {
+				f.cursorToNext(screenHeight)
+			} else {
+				f.cursorToPrev()
 			}
-			if f.state.cursorY > 0 {
-				f.state.cursorY--
+		case tcell.KeyCtrlT:
+			if !f.opt.follow {
+				return nil
 			}
+			f.state.following = true
+			f.moveCursorToNewest()
 		default:
 			if e.Rune() != 0 {
-				width, _ := f.term.Size()
+				width, _ := f.listPane().Size()
 				maxLineWidth := width - 2 - 1
 				if len(f.state.input)+1 > maxLineWidth {
 					// Discard inputted rune.
@@ -642,14 +1293,17 @@ func (f *finder) readKey(ctx context.Context) error {
 				f.state.x++
 			}
 		}
+	case *tcell.EventMouse:
+		f.previewMouse(e)
 	case *tcell.EventResize:
 		f.term.Clear()
 
-		width, height := f.term.Size()
+		width, height := f.listPane().Size()
 		itemAreaHeight := height - 2 - 1
 		if itemAreaHeight >= 0 && f.state.cursorY > itemAreaHeight {
 			f.state.cursorY = itemAreaHeight
 		}
+		f.fitCursor()
 
 		maxLineWidth := width - 2 - 1
 		if maxLineWidth < 0 {
@@ -667,27 +1321,58 @@ func (f *finder) readKey(ctx context.Context) error {
 }
 
 func (f *finder) filter() {
+	if f.opt.treeParent != nil {
+		f.filterTree()
+		return
+	}
+
 	f.stateMu.RLock()
-	if len(f.state.input) == 0 {
+	if len(f.state.input) == 0 || !f.filtersLocally() {
 		f.stateMu.RUnlock()
 		f.stateMu.Lock()
 		defer f.stateMu.Unlock()
-		f.state.matched = f.state.allMatched
+		f.state.matchAll()
+		if f.state.following {
+			f.state.cursorKey = nil
+			f.moveCursorToNewest()
+			return
+		}
+		f.followCursorKey()
 		return
 	}
 
 	// TODO: If input is not delete operation, it is able to
 	// reduce total iteration.
 	// FindAll may take a lot of time, so it is desired to use RLock to avoid goroutine blocking.
-	matchedItems := matching.FindAll(string(f.state.input), f.state.items, matching.WithMode(matching.Mode(f.opt.mode)))
+	matchedItems := matching.FindAllFunc(string(f.state.input), f.state.items.len(), f.state.items.at, matching.WithMode(matching.Mode(f.opt.mode)))
+	if f.opt.group != nil {
+		f.groupMatched(matchedItems)
+	}
+	version := f.state.version
 	f.stateMu.RUnlock()
 
 	f.stateMu.Lock()
 	defer f.stateMu.Unlock()
+	defer f.fitCursor()
+	if version != f.state.version {
+		// The items are changed while filtering, so filter them again.
+		defer f.requestFilter()
+		return
+	}
 	f.state.matched = matchedItems
+	f.state.allMatched = false
 	if len(f.state.matched) == 0 {
 		f.state.cursorY = 0
 		f.state.y = 0
+		f.state.cursorKey = nil
+		return
+	}
+	if f.state.following {
+		f.state.cursorKey = nil
+		f.moveCursorToNewest()
+		return
+	}
+	if f.followCursorKey() {
 		return
 	}
 
@@ -695,7 +1380,7 @@ func (f *finder) filter() {
 	// that's still in the matched results
 	if !f.opt.multi {
 		for i, m := range f.state.matched {
-			if f.opt.preselected(m.Idx) {
+			if f.isPreselected(m.Idx) {
 				f.state.y = i
 				f.state.cursorY = min(i, len(f.state.matched)-1)
 				return
@@ -713,36 +1398,104 @@ func (f *finder) filter() {
 }
 
 func (f *finder) find(slice interface{}, itemFunc func(i int) string, opts []Option) ([]int, error) {
-	if itemFunc == nil {
-		return nil, errors.New("itemFunc must not be nil")
-	}
-
 	opt := defaultOption
 	for _, o := range opts {
 		o(&opt)
 	}
 
-	rv := reflect.ValueOf(slice)
-	if opt.hotReload && (rv.Kind() != reflect.Ptr || reflect.Indirect(rv).Kind() != reflect.Slice) {
-		return nil, errors.Errorf("the first argument must be a pointer to a slice, but got %T", slice)
-	} else if !opt.hotReload && rv.Kind() != reflect.Slice {
-		return nil, errors.Errorf("the first argument must be a slice, but got %T", slice)
+	if opt.tableRow != nil {
+		itemFunc = opt.tableText
+	}
+
+	src := opt.itemSource
+	if src == nil && (opt.querySource != nil || opt.commandName != "") {
+		// The items are provided by the query source or the command.
+		src = &sliceSource{}
 	}
+	if src == nil {
+		if itemFunc == nil {
+			return nil, errors.New("itemFunc must not be nil")
+		}
+
+		rv := reflect.ValueOf(slice)
+		if opt.hotReload && (rv.Kind() != reflect.Ptr || reflect.Indirect(rv).Kind() != reflect.Slice) {
+			return nil, errors.Errorf("the first argument must be a pointer to a slice, but got %T", slice)
+		} else if !opt.hotReload && rv.Kind() != reflect.Slice {
+			return nil, errors.Errorf("the first argument must be a slice, but got %T", slice)
+		}
 
-	makeItems := func(sliceLen int) ([]string, []matching.Matched) {
-		items := make([]string, sliceLen)
-		matched := make([]matching.Matched, sliceLen)
-		for i := 0; i < sliceLen; i++ {
-			items[i] = itemFunc(i)
-			matched[i] = matching.Matched{Idx: i} //nolint:exhaustivestruct
+		if opt.hotReload {
+			src = newHotReloadSource(reflect.Indirect(rv), itemFunc, opt.itemKey, opt.hotReloadLock)
+		} else {
+			src = &sliceSource{n: rv.Len(), itemFunc: itemFunc}
 		}
-		return items, matched
 	}
 
-	var (
-		items   []string
-		matched []matching.Matched
-	)
+	n := src.Len()
+	var items, rawItems itemList
+	for i := 0; i < n; i++ {
+		s := src.Item(i)
+		if opt.ansi {
+			rawItems.append(s)
+			s = stripANSI(s)
+		}
+		items.append(s)
+	}
+	var keys []string
+	switch {
+	case opt.itemKey != nil:
+		keys = make([]string, n)
+		for i := 0; i < n; i++ {
+			if k, ok := src.(keyer); ok {
+				keys[i] = k.key(i)
+			} else {
+				keys[i] = opt.itemKey(i)
+			}
+		}
+	case opt.reloader != nil, opt.querySource != nil, opt.commandName != "":
+		// Without WithItemKey, the items themselves are used as the keys to
+		// keep the cursor and the selections on reload.
+		keys = make([]string, n)
+		for i := 0; i < n; i++ {
+			keys[i] = items.at(i)
+		}
+	}
+
+	if opt.reloadKeyName != "" {
+		if opt.reloader == nil && opt.commandName == "" {
+			return nil, errors.New("the loader passed to WithReload must not be nil")
+		}
+		b, err := parseBindableKey(opt.reloadKeyName)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid reload key")
+		}
+		opt.reloadKey = b
+	}
+
+	if opt.heightSpec != "" {
+		h, err := parseHeight(opt.heightSpec)
+		if err != nil {
+			return nil, err
+		}
+		opt.height = h
+	}
+
+	if opt.previewToggleKeyName != "" {
+		b, err := parseBindableKey(opt.previewToggleKeyName)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid preview toggle key")
+		}
+		if opt.reloadKeyName != "" && b == opt.reloadKey {
+			return nil, errors.Errorf("invalid preview toggle key: %s is bound to reloading", opt.previewToggleKeyName)
+		}
+		opt.previewToggleKey = b
+	}
+
+	previewSize, err := parsePreviewSize(opt.previewSizeSpec)
+	if err != nil {
+		return nil, err
+	}
+	opt.previewSize = previewSize
 
 	var parentContext context.Context
 	if opt.context != nil {
@@ -754,49 +1507,41 @@ func (f *finder) find(slice interface{}, itemFunc func(i int) string, opts []Opt
 	ctx, cancel := context.WithCancel(parentContext)
 	defer cancel()
 
-	inited := make(chan struct{})
-	if opt.hotReload && rv.Kind() == reflect.Ptr {
-		opt.hotReloadLock.Lock()
-		rvv := reflect.Indirect(rv)
-		items, matched = makeItems(rvv.Len())
-		opt.hotReloadLock.Unlock()
-
-		go func() {
-			<-inited
-
-			var prev int
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case <-time.After(30 * time.Millisecond):
-					opt.hotReloadLock.Lock()
-					curr := rvv.Len()
-					if prev != curr {
-						items, matched = makeItems(curr)
-						f.updateItems(items, matched)
-					}
-					opt.hotReloadLock.Unlock()
-					prev = curr
-				}
-			}
-		}()
-	} else {
-		items, matched = makeItems(rv.Len())
-	}
-
-	if err := f.initFinder(items, matched, opt); err != nil {
+	if err := f.initFinder(items, rawItems, keys, opt); err != nil {
 		return nil, errors.Wrap(err, "failed to initialize the fuzzy finder")
 	}
+	f.previewer = nil
+	if opt.asyncPreview {
+		f.previewer = newPreviewer(ctx, opt.previewCacheSize)
+	}
 
 	if !isInTesting() {
 		defer f.term.Fini()
 	}
 
-	close(inited)
+	if l, ok := src.(loader); ok {
+		f.state.loading = l.loading()
+		go f.watchLoading(ctx, l.loading)
+	}
+	if changes := src.Changes(); changes != nil {
+		go f.receiveChanges(ctx, changes)
+	}
+	if w, ok := src.(watcher); ok {
+		go w.watch(ctx, f.applyChanges)
+	}
+	if opt.querySource != nil {
+		f.stateMu.Lock()
+		f.loadQuery(ctx)
+		f.stateMu.Unlock()
+	}
+	if opt.commandName != "" {
+		f.stateMu.Lock()
+		f.runCommand(ctx)
+		f.stateMu.Unlock()
+	}
 
-	if opt.selectOne && len(f.state.matched) == 1 {
-		return []int{f.state.matched[0].Idx}, nil
+	if opt.selectOne && f.state.matchedLen() == 1 {
+		return []int{f.state.matchedAt(0).Idx}, nil
 	}
 
 	go func() {
@@ -805,6 +1550,15 @@ func (f *finder) find(slice interface{}, itemFunc func(i int) string, opts []Opt
 			case <-ctx.Done():
 				return
 			case <-f.eventCh:
+				// Coalesce queued events into one filtering.
+			DRAIN:
+				for {
+					select {
+					case <-f.eventCh:
+					default:
+						break DRAIN
+					}
+				}
 				f.filter()
 				f.draw(0)
 			}
@@ -830,24 +1584,24 @@ func (f *finder) find(slice interface{}, itemFunc func(i int) string, opts []Opt
 				f.stateMu.RLock()
 				defer f.stateMu.RUnlock()
 
-				if len(f.state.matched) == 0 {
+				if f.state.matchedLen() == 0 {
 					return nil, ErrAbort
 				}
 				if f.opt.multi {
 					if len(f.state.selection) == 0 {
-						return []int{f.state.matched[f.state.y].Idx}, nil
+						return []int{f.state.matchedAt(f.state.y).Idx}, nil
 					}
-					poss, idxs := make([]int, 0, len(f.state.selection)), make([]int, 0, len(f.state.selection))
-					for idx, pos := range f.state.selection {
+					idxs := make([]int, 0, len(f.state.selection))
+					for idx := range f.state.selection {
 						idxs = append(idxs, idx)
-						poss = append(poss, pos)
 					}
+					// Sort the items in the order in which they are selected.
 					sort.Slice(idxs, func(i, j int) bool {
-						return poss[i] < poss[j]
+						return f.state.selection[idxs[i]] < f.state.selection[idxs[j]]
 					})
 					return idxs, nil
 				}
-				return []int{f.state.matched[f.state.y].Idx}, nil
+				return []int{f.state.matchedAt(f.state.y).Idx}, nil
 			case err != nil:
 				return nil, errors.Wrap(err, "failed to read a key")
 			}
@@ -859,7 +1613,9 @@ func (f *finder) find(slice interface{}, itemFunc func(i int) string, opts []Opt
 // The argument slice must be of a slice type. If not, Find returns
 // an error. itemFunc is called by the length of slice. previewFunc is called
 // when the cursor which points to the currently selected item is changed.
-// If itemFunc is nil, Find returns an error.
+// If itemFunc is nil, Find returns an error. If WithItemSource,
+// WithQuerySource or WithCommandSource is passed, slice and itemFunc are
+// ignored and may be nil.
 //
 // itemFunc receives an argument i, which is the index of the item currently
 // selected.
@@ -892,10 +1648,75 @@ func (f *finder) FindMulti(slice interface{}, itemFunc func(i int) string, opts
 	return res, err
 }
 
+// FindT is a type-safe version of Find. label is called with each item to
+// get its display string. FindT returns the selected item itself instead of
+// its index.
+//
+// WithItemSource, WithReload, WithQuerySource and WithCommandSource can't be
+// used with FindT because they replace items with strings. FindT returns an
+// error if any of them is passed.
+//
+// FindT returns ErrAbort if a call to FindT is finished with no selection.
+func FindT[T any](items []T, label func(T) string, opts ...Option) (T, error) {
+	res, err := findT(newFinder(), items, label, opts)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return res[0], nil
+}
+
+// FindMultiT is a type-safe version of FindMulti. It returns the selected
+// items in the order they were selected.
+func FindMultiT[T any](items []T, label func(T) string, opts ...Option) ([]T, error) {
+	return findT(newFinder(), items, label, append(opts, withMulti()))
+}
+
+func findT[T any](f *finder, items []T, label func(T) string, opts []Option) ([]T, error) {
+	if label == nil {
+		return nil, errors.New("label must not be nil")
+	}
+	if err := checkTypedOptions(opts); err != nil {
+		return nil, err
+	}
+
+	idxs, err := f.find(items, func(i int) string { return label(items[i]) }, opts)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]T, len(idxs))
+	for i, idx := range idxs {
+		res[i] = items[idx]
+	}
+	return res, nil
+}
+
+// checkTypedOptions returns an error if opts contain an option which replaces
+// the items. The type-safe functions look up the selected items by the
+// returned indices, which are the ones of the replaced items.
+func checkTypedOptions(opts []Option) error {
+	opt := defaultOption
+	for _, o := range opts {
+		o(&opt)
+	}
+	if opt.itemSource != nil || opt.reloadKeyName != "" || opt.querySource != nil || opt.commandName != "" {
+		return errors.New("WithItemSource, WithReload, WithQuerySource and WithCommandSource can't be used with typed items")
+	}
+	return nil
+}
+
 func isInTesting() bool {
 	return flag.Lookup("test.v") != nil
 }
 
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// spinnerFrame returns the frame of the loading spinner for the current time.
+func spinnerFrame() rune {
+	n := time.Now().UnixNano() / int64(100*time.Millisecond)
+	return spinnerFrames[n%int64(len(spinnerFrames))]
+}
+
 func consumeIterator(iter *ansisgr.Iterator, r rune) {
 	for {
 		r, _, ok := iter.Next()
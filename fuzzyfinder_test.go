@@ -3,6 +3,7 @@ package fuzzyfinder_test
 import (
 	"context"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -361,6 +362,1244 @@ func TestFind_hotReloadLock(t *testing.T) {
 	})
 }
 
+func TestFind_hotReloadIncremental(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	events := append(runes("ba"), keys([]input{
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+	}...)...)
+	term.SetEventsV2(events...)
+
+	var mu sync.RWMutex
+	items := []string{"foo", "bar"}
+	calls := map[int]int{}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		mu.Lock()
+		items = append(items, "qux", "baz")
+		mu.Unlock()
+	}()
+
+	assertWithGolden(t, func(t *testing.T) string {
+		idx, err := f.Find(
+			&items,
+			func(i int) string {
+				calls[i]++
+				return items[i]
+			},
+			fuzzyfinder.WithHotReloadLock(mu.RLocker()),
+		)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		// "baz" is matched, but the cursor is kept on "bar".
+		if idx != 1 {
+			t.Errorf("expected index: 1, but got %d", idx)
+		}
+		return term.GetResult()
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := cmp.Diff(map[int]int{0: 1, 1: 1, 2: 1, 3: 1}, calls); diff != "" {
+		t.Errorf("itemFunc must be called only once for each item: \n%s", diff)
+	}
+}
+
+func TestFind_WithFollow(t *testing.T) {
+	t.Parallel()
+
+	wait := keys([]input{
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+	}...)
+	enter := key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone})
+	down := key(input{tcell.KeyDown, rune(tcell.KeyDown), tcell.ModNone})
+	resume := key(input{tcell.KeyCtrlT, 'T', tcell.ModCtrl})
+
+	cases := map[string]struct {
+		events   []tcell.Event
+		expected int
+	}{
+		"follow":  {events: append(wait, enter), expected: 4},
+		"paused":  {events: append(append([]tcell.Event{down}, wait...), enter), expected: 1},
+		"resumed": {events: append(append([]tcell.Event{down}, wait...), resume, enter), expected: 4},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			term.SetEventsV2(c.events...)
+
+			var mu sync.RWMutex
+			items := []string{"log0", "log1", "log2"}
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				mu.Lock()
+				items = append(items, "log3", "log4")
+				mu.Unlock()
+			}()
+
+			assertWithGolden(t, func(t *testing.T) string {
+				idx, err := f.Find(
+					&items,
+					func(i int) string { return items[i] },
+					fuzzyfinder.WithHotReloadLock(mu.RLocker()),
+					fuzzyfinder.WithFollow(),
+				)
+				if err != nil {
+					t.Fatalf("Find must not return an error, but got '%s'", err)
+				}
+				if idx != c.expected {
+					t.Errorf("expected index: %d, but got %d", c.expected, idx)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+}
+
+func TestFind_WithReload(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		loader   func(ctx context.Context) ([]string, error)
+		expected int
+	}{
+		"normal": {
+			loader: func(ctx context.Context) ([]string, error) {
+				return []string{"x", "a", "b", "c"}, nil
+			},
+			expected: 2,
+		},
+		"error": {
+			loader: func(ctx context.Context) ([]string, error) {
+				return nil, errors.New("failed to list items")
+			},
+			expected: 1,
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			term.SetEventsV2(keys([]input{
+				{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+				{tcell.KeyCtrlR, 'R', tcell.ModCtrl},
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+				{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+			}...)...)
+
+			items := []string{"a", "b", "c"}
+			assertWithGolden(t, func(t *testing.T) string {
+				idx, err := f.Find(
+					items,
+					func(i int) string { return items[i] },
+					fuzzyfinder.WithReload("ctrl-r", c.loader),
+				)
+				if err != nil {
+					t.Fatalf("Find must not return an error, but got '%s'", err)
+				}
+				// The cursor is kept on "b".
+				if idx != c.expected {
+					t.Errorf("expected index: %d, but got %d", c.expected, idx)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+
+	for _, k := range []string{"ctrl-foo", "ctrl-p"} {
+		k := k
+		t.Run("invalid key "+k, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := fuzzyfinder.New().Find(
+				[]string{"a"},
+				func(i int) string { return "a" },
+				fuzzyfinder.WithReload(k, func(ctx context.Context) ([]string, error) { return nil, nil }),
+			)
+			if err == nil {
+				t.Error("Find must return an error, but got nil")
+			}
+		})
+	}
+}
+
+func TestFind_WithQuerySource(t *testing.T) {
+	t.Parallel()
+
+	source := func(ctx context.Context, query string) ([]string, error) {
+		if query == "" {
+			return []string{"foo", "bar"}, nil
+		}
+		return []string{"foo " + query, "bar " + query, "baz"}, nil
+	}
+
+	cases := map[string]struct {
+		source   func(ctx context.Context, query string) ([]string, error)
+		opts     []fuzzyfinder.Option
+		expected int
+	}{
+		"normal": {
+			source:   source,
+			expected: 1,
+		},
+		"filter": {
+			source:   source,
+			opts:     []fuzzyfinder.Option{fuzzyfinder.WithQuerySourceFilter()},
+			expected: 1,
+		},
+		"error": {
+			source: func(ctx context.Context, query string) ([]string, error) {
+				if query == "" {
+					return []string{"foo", "bar"}, nil
+				}
+				return nil, errors.New("search failed")
+			},
+			expected: 1,
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu      sync.Mutex
+				queries []string
+			)
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			term.SetEventsV2(keys([]input{
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+				{tcell.KeyRune, 'b', tcell.ModNone},
+				{tcell.KeyRune, 'a', tcell.ModNone},
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+				{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+				{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+			}...)...)
+
+			assertWithGolden(t, func(t *testing.T) string {
+				opts := append([]fuzzyfinder.Option{
+					fuzzyfinder.WithQuerySource(func(ctx context.Context, query string) ([]string, error) {
+						mu.Lock()
+						queries = append(queries, query)
+						mu.Unlock()
+						return c.source(ctx, query)
+					}),
+				}, c.opts...)
+				idx, err := f.Find(nil, nil, opts...)
+				if err != nil {
+					t.Fatalf("Find must not return an error, but got '%s'", err)
+				}
+				if idx != c.expected {
+					t.Errorf("expected index: %d, but got %d", c.expected, idx)
+				}
+				return term.GetResult()
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(queries) == 0 || queries[0] != "" || queries[len(queries)-1] != "ba" {
+				t.Errorf("the source must be called with the initial and the last queries, but got %q", queries)
+			}
+		})
+	}
+}
+
+func TestFindQuery(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetEventsV2(keys([]input{
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyRune, 'b', tcell.ModNone},
+		{tcell.KeyRune, 'a', tcell.ModNone},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+		{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+	}...)...)
+
+	item, err := f.FindQuery(func(ctx context.Context, query string) ([]string, error) {
+		return []string{"foo " + query, "bar " + query, "baz"}, nil
+	})
+	if err != nil {
+		t.Fatalf("FindQuery must not return an error, but got '%s'", err)
+	}
+	// The selected item is the one returned for the last query.
+	if item != "bar ba" {
+		t.Errorf("expected item: 'bar ba', but got '%s'", item)
+	}
+
+	t.Run("source is nil", func(t *testing.T) {
+		_, err := fuzzyfinder.New().FindQuery(nil)
+		if err == nil {
+			t.Error("FindQuery must return an error, but got nil")
+		}
+	})
+}
+
+func TestFind_WithMultiLineItems(t *testing.T) {
+	t.Parallel()
+
+	items := []string{
+		"foo\nbar",
+		"one line",
+		"1\n2\n3\n4\n5\n6\n7",
+		"baz\nqux\nquux",
+		"last",
+	}
+	cases := map[string]struct {
+		events   []input
+		expected int
+	}{
+		"initial": {
+			expected: 0,
+		},
+		"up": {
+			events: []input{
+				{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+				{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+				{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+			},
+			expected: 3,
+		},
+		"pgup": {
+			events: []input{
+				{tcell.KeyPgUp, rune(tcell.KeyPgUp), tcell.ModNone},
+			},
+			expected: 2,
+		},
+		"pgup and pgdn": {
+			events: []input{
+				{tcell.KeyPgUp, rune(tcell.KeyPgUp), tcell.ModNone},
+				{tcell.KeyPgUp, rune(tcell.KeyPgUp), tcell.ModNone},
+				{tcell.KeyPgDn, rune(tcell.KeyPgDn), tcell.ModNone},
+			},
+			expected: 2,
+		},
+		"query": {
+			events: []input{
+				{tcell.KeyRune, 'o', tcell.ModNone},
+				{tcell.KeyRune, 'b', tcell.ModNone},
+				{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+			},
+			expected: 0,
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			term.SetSize(40, 10)
+			events := append(c.events, input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone})
+			term.SetEventsV2(keys(events...)...)
+
+			assertWithGolden(t, func(t *testing.T) string {
+				idx, err := f.Find(
+					items,
+					func(i int) string { return items[i] },
+					fuzzyfinder.WithMultiLineItems(),
+				)
+				if err != nil {
+					t.Fatalf("Find must not return an error, but got '%s'", err)
+				}
+				if idx != c.expected {
+					t.Errorf("expected index: %d, but got %d", c.expected, idx)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+}
+
+func TestFind_WithANSI(t *testing.T) {
+	t.Parallel()
+
+	items := []string{
+		"\x1b[31mred\x1b[0m item",
+		"\x1b[1;92mgreen\x1b[0m",
+		"plain",
+	}
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		events := append(runes("rd"), key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+		term.SetEventsV2(events...)
+
+		assertWithGolden(t, func(t *testing.T) string {
+			idx, err := f.Find(
+				items,
+				func(i int) string { return items[i] },
+				fuzzyfinder.WithANSI(),
+			)
+			if err != nil {
+				t.Fatalf("Find must not return an error, but got '%s'", err)
+			}
+			if idx != 0 {
+				t.Errorf("expected index: 0, but got %d", idx)
+			}
+			return term.GetResult()
+		})
+	})
+
+	t.Run("FindReader", func(t *testing.T) {
+		t.Parallel()
+
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetEventsV2(keys([]input{
+			{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+			{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+		}...)...)
+
+		_, item, err := f.FindReader(strings.NewReader(strings.Join(items, "\n")), fuzzyfinder.WithANSI())
+		if err != nil {
+			t.Fatalf("FindReader must not return an error, but got '%s'", err)
+		}
+		if item != "red item" {
+			t.Errorf("expected item: red item, but got %q", item)
+		}
+	})
+}
+
+func TestFind_WithItemStyle(t *testing.T) {
+	t.Parallel()
+
+	items := []string{"ok foo", "fail bar", "dirty baz", "ok qux"}
+	styles := map[int]fuzzyfinder.Style{
+		1: {Foreground: fuzzyfinder.ColorRed, Bold: true},
+		2: {Foreground: fuzzyfinder.ColorYellow, Background: fuzzyfinder.RGBColor(0x30, 0x30, 0x30)},
+		3: {Foreground: fuzzyfinder.PaletteColor(244), Italic: true},
+	}
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	events := append(runes("a"), key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+	term.SetEventsV2(events...)
+
+	assertWithGolden(t, func(t *testing.T) string {
+		idx, err := f.Find(
+			items,
+			func(i int) string { return items[i] },
+			fuzzyfinder.WithItemStyle(func(i int) fuzzyfinder.Style { return styles[i] }),
+		)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		if idx != 1 {
+			t.Errorf("expected index: 1, but got %d", idx)
+		}
+		return term.GetResult()
+	})
+}
+
+func TestFind_WithAnnotation(t *testing.T) {
+	t.Parallel()
+
+	items := []string{"main.go", "a very long file name which is truncated.txt", "日本語のファイル名a.md", "README"}
+	// "a" is matched against the items only.
+	annotations := []string{"1.2KB", "3日前", "a day ago", "an annotation which is too long to display"}
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetSize(40, 10)
+	events := append(runes("a"), key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+	term.SetEventsV2(events...)
+
+	assertWithGolden(t, func(t *testing.T) string {
+		_, err := f.Find(
+			items,
+			func(i int) string { return items[i] },
+			fuzzyfinder.WithAnnotation(func(i int) string { return annotations[i] }),
+		)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		return term.GetResult()
+	})
+}
+
+func TestFind_WithTable(t *testing.T) {
+	t.Parallel()
+
+	type file struct{ name, size, desc string }
+	files := []file{
+		{"main.go", "1024", "entry point"},
+		{"README.md", "12", "a description which is too long"},
+		{"go.mod", "256", "module"},
+		{"日本語.txt", "8", "go"},
+	}
+	columns := []fuzzyfinder.Column{
+		{Header: "NAME"},
+		// "1" is not matched against the sizes.
+		{Header: "SIZE", Align: fuzzyfinder.AlignRight, NoMatch: true},
+		{Header: "DESCRIPTION", MaxWidth: 12},
+	}
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetSize(40, 10)
+	events := append(runes("go1"), keys(input{tcell.KeyBackspace2, rune(tcell.KeyBackspace2), tcell.ModNone})...)
+	events = append(events, keys(input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone})...)
+	events = append(events, key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+	term.SetEventsV2(events...)
+
+	assertWithGolden(t, func(t *testing.T) string {
+		idx, err := f.Find(
+			files,
+			nil,
+			fuzzyfinder.WithTable(columns, func(i int) []string {
+				return []string{files[i].name, files[i].size, files[i].desc}
+			}),
+		)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		if idx != 3 {
+			t.Errorf("expected index: 3, but got %d", idx)
+		}
+		return term.GetResult()
+	})
+}
+
+func TestFind_WithGroups(t *testing.T) {
+	t.Parallel()
+
+	items := []string{"main", "feature/login", "develop", "feature/search", "fix/crash", "v1.0.0", "release-2"}
+	groups := []string{"Recent", "Recent", "Branches", "Branches", "Branches", "Tags", "Tags"}
+	up := input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}
+
+	cases := map[string]struct {
+		events []tcell.Event
+		opts   []fuzzyfinder.Option
+	}{
+		"initial": {},
+		"filter":  {events: runes("ea")},
+		// The cursor skips the header of Branches.
+		"cursor up":           {events: append(runes("ea"), keys(up, up)...)},
+		"rank across groups":  {events: runes("ea"), opts: []fuzzyfinder.Option{fuzzyfinder.WithRankAcrossGroups()}},
+		"scroll with headers": {events: keys(up, up, up, up, up, up)},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			term.SetSize(40, 10)
+			events := append(c.events, key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+			term.SetEventsV2(events...)
+
+			opts := append([]fuzzyfinder.Option{
+				fuzzyfinder.WithGroups(func(i int) string { return groups[i] }),
+			}, c.opts...)
+			assertWithGolden(t, func(t *testing.T) string {
+				_, err := f.Find(items, func(i int) string { return items[i] }, opts...)
+				if !errors.Is(err, fuzzyfinder.ErrAbort) {
+					t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+}
+
+func TestFind_WithGroups_hotReload(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetSize(40, 10)
+	events := append(runes("a"), keys([]input{
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone},
+	}...)...)
+	term.SetEventsV2(events...)
+
+	var mu sync.RWMutex
+	items := []string{"xa", "xxa", "ya", "yya"}
+	// The groups of the items including the appended ones.
+	groups := []string{"G1", "G1", "G2", "G2", "G1", "G2"}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		mu.Lock()
+		items = append(items, "a", "aa")
+		mu.Unlock()
+	}()
+
+	assertWithGolden(t, func(t *testing.T) string {
+		_, err := f.Find(
+			&items,
+			func(i int) string { return items[i] },
+			fuzzyfinder.WithGroups(func(i int) string { return groups[i] }),
+			fuzzyfinder.WithHotReloadLock(mu.RLocker()),
+		)
+		if !errors.Is(err, fuzzyfinder.ErrAbort) {
+			t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+		}
+		// The appended items are displayed in their groups.
+		return term.GetResult()
+	})
+}
+
+var treeItems = []struct {
+	name   string
+	parent int
+}{
+	{"src", -1},
+	{"main.go", 0},
+	{"pkg", 0},
+	{"util.go", 2},
+	{"util_test.go", 2},
+	{"docs", -1},
+	{"README.md", 5},
+}
+
+func TestFind_WithTree(t *testing.T) {
+	t.Parallel()
+
+	up := input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}
+	down := input{tcell.KeyDown, rune(tcell.KeyDown), tcell.ModNone}
+	expand := input{tcell.KeyRight, rune(tcell.KeyRight), tcell.ModShift}
+	collapse := input{tcell.KeyLeft, rune(tcell.KeyLeft), tcell.ModShift}
+
+	cases := map[string]struct {
+		events []tcell.Event
+	}{
+		"initial":          {},
+		"expand":           {events: keys(up, expand)},
+		"expand nested":    {events: keys(up, expand, down, down, expand)},
+		"collapse":         {events: keys(up, expand, collapse)},
+		"collapse parent":  {events: keys(up, expand, down, collapse)},
+		"filter":           {events: runes("util")},
+		"filter and clear": {events: append(runes("u"), keys(input{tcell.KeyBackspace2, rune(tcell.KeyBackspace2), tcell.ModNone})...)},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			term.SetSize(30, 10)
+			events := append(c.events, key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+			term.SetEventsV2(events...)
+
+			assertWithGolden(t, func(t *testing.T) string {
+				_, err := f.Find(
+					treeItems,
+					func(i int) string { return treeItems[i].name },
+					fuzzyfinder.WithTree(func(i int) int { return treeItems[i].parent }),
+				)
+				if !errors.Is(err, fuzzyfinder.ErrAbort) {
+					t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+}
+
+func TestFind_WithTreeResult(t *testing.T) {
+	t.Parallel()
+
+	parent := fuzzyfinder.WithTree(func(i int) int { return treeItems[i].parent })
+	leaves := fuzzyfinder.WithTreeResult(fuzzyfinder.TreeResultLeaves)
+	itemFunc := func(i int) string { return treeItems[i].name }
+	enter := input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}
+	up := input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}
+	down := input{tcell.KeyDown, rune(tcell.KeyDown), tcell.ModNone}
+	tab := input{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone}
+
+	t.Run("nodes", func(t *testing.T) {
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetEventsV2(keys(enter)...)
+		idx, err := f.Find(treeItems, itemFunc, parent)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		if idx != 5 {
+			t.Errorf("expected index: 5, but got %d", idx)
+		}
+	})
+	t.Run("leaves", func(t *testing.T) {
+		// Enter on docs expands it instead of accepting it.
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetEventsV2(keys(enter, down, enter)...)
+		idx, err := f.Find(treeItems, itemFunc, parent, leaves)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		if idx != 6 {
+			t.Errorf("expected index: 6, but got %d", idx)
+		}
+	})
+	t.Run("leaves multi", func(t *testing.T) {
+		// Tab on src selects the leaves under it.
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetEventsV2(keys(up, tab, enter)...)
+		idxs, err := f.FindMulti(treeItems, itemFunc, parent, leaves)
+		if err != nil {
+			t.Fatalf("FindMulti must not return an error, but got '%s'", err)
+		}
+		if diff := cmp.Diff([]int{1, 3, 4}, idxs); diff != "" {
+			t.Errorf("wrong indices: \n%s", diff)
+		}
+	})
+	t.Run("page up without matches", func(t *testing.T) {
+		// The cursor must stay in range so that expanding after clearing the
+		// query doesn't panic.
+		pgUp := input{tcell.KeyPgUp, rune(tcell.KeyPgUp), tcell.ModNone}
+		backspace := input{tcell.KeyBackspace2, rune(tcell.KeyBackspace2), tcell.ModNone}
+		expand := input{tcell.KeyRight, rune(tcell.KeyRight), tcell.ModShift}
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetEventsV2(append(runes("z"), keys(pgUp, backspace, expand, enter)...)...)
+		idx, err := f.Find(treeItems, itemFunc, parent)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		if idx != 5 {
+			t.Errorf("expected index: 5, but got %d", idx)
+		}
+	})
+}
+
+func TestFindNested(t *testing.T) {
+	t.Parallel()
+
+	clusters := []string{"prod", "staging", "preprod"}
+	children := func(_ context.Context, path []int) ([]string, error) {
+		switch len(path) {
+		case 1:
+			return []string{"default", "kube-system", clusters[path[0]] + "-app"}, nil
+		case 2:
+			if path[1] == 1 {
+				return nil, errors.New("forbidden")
+			}
+			return []string{"web-1", "web-2"}, nil
+		}
+		return nil, nil
+	}
+	enter := input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}
+	backspace := input{tcell.KeyBackspace2, rune(tcell.KeyBackspace2), tcell.ModNone}
+	up := input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}
+
+	cases := map[string]struct {
+		events []tcell.Event
+	}{
+		"drill down": {events: keys(enter, enter)},
+		// The query and the cursor of the clusters are restored.
+		"back":  {events: append(runes("p"), keys(up, enter, backspace)...)},
+		"error": {events: keys(enter, up, enter)},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			term.SetSize(40, 10)
+			events := append(c.events, key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+			term.SetEventsV2(events...)
+
+			assertWithGolden(t, func(t *testing.T) string {
+				_, err := f.FindNested(clusters, children, fuzzyfinder.WithHeader("clusters"))
+				if !errors.Is(err, fuzzyfinder.ErrAbort) {
+					t.Fatalf("FindNested must return ErrAbort, but got '%s'", err)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+
+	t.Run("path", func(t *testing.T) {
+		t.Parallel()
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetEventsV2(keys(up, enter, up, up, enter, up, enter)...)
+
+		path, err := f.FindNested(clusters, children)
+		if err != nil {
+			t.Fatalf("FindNested must not return an error, but got '%s'", err)
+		}
+		if diff := cmp.Diff([]int{1, 2, 1}, path); diff != "" {
+			t.Errorf("wrong path: \n%s", diff)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetSize(40, 10)
+		esc := input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}
+		// Esc cancels loading the children of prod instead of aborting, and Up
+		// is ignored while loading.
+		term.SetEventsV2(keys(enter, up, esc, up, enter, esc)...)
+
+		cancelled := make(chan struct{})
+		slowChildren := func(ctx context.Context, path []int) ([]string, error) {
+			if path[0] == 0 {
+				<-ctx.Done()
+				close(cancelled)
+				return nil, ctx.Err()
+			}
+			return children(ctx, path)
+		}
+		assertWithGolden(t, func(t *testing.T) string {
+			_, err := f.FindNested(clusters, slowChildren, fuzzyfinder.WithHeader("clusters"))
+			if !errors.Is(err, fuzzyfinder.ErrAbort) {
+				t.Fatalf("FindNested must return ErrAbort, but got '%s'", err)
+			}
+			return term.GetResult()
+		})
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Error("the context passed to children must be cancelled")
+		}
+	})
+}
+
+func TestFind_WithLayout(t *testing.T) {
+	t.Parallel()
+
+	up := input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}
+	down := input{tcell.KeyDown, rune(tcell.KeyDown), tcell.ModNone}
+	pgDn := input{tcell.KeyPgDn, rune(tcell.KeyPgDn), tcell.ModNone}
+	layouts := map[string]fuzzyfinder.Layout{
+		"default":      fuzzyfinder.LayoutDefault,
+		"reverse":      fuzzyfinder.LayoutReverse,
+		"reverse list": fuzzyfinder.LayoutReverseList,
+	}
+	cases := map[string]struct {
+		events []tcell.Event
+		opts   []fuzzyfinder.Option
+	}{
+		"initial": {opts: []fuzzyfinder.Option{fuzzyfinder.WithHeader("Search?")}},
+		// The cursor moves down on the screen.
+		"down":  {events: keys(up, up, up, down)},
+		"pg-dn": {events: keys(pgDn)},
+		"groups": {
+			events: runes("a"),
+			opts: []fuzzyfinder.Option{fuzzyfinder.WithGroups(func(i int) string {
+				if tracks[i].Artist == "" {
+					return "Unknown"
+				}
+				return string([]rune(tracks[i].Artist)[:1])
+			})},
+		},
+	}
+
+	for lname, layout := range layouts {
+		for name, c := range cases {
+			layout, c := layout, c
+
+			t.Run(lname+"/"+name, func(t *testing.T) {
+				t.Parallel()
+				f, term := fuzzyfinder.NewWithMockedTerminal()
+				events := append(c.events, key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+				term.SetEventsV2(events...)
+
+				opts := append([]fuzzyfinder.Option{fuzzyfinder.WithLayout(layout)}, c.opts...)
+				assertWithGolden(t, func(t *testing.T) string {
+					_, err := f.Find(tracks, func(i int) string { return tracks[i].Name }, opts...)
+					if !errors.Is(err, fuzzyfinder.ErrAbort) {
+						t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+					}
+					return term.GetResult()
+				})
+			})
+		}
+	}
+}
+
+func TestFind_WithPreviewLayout(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		position fuzzyfinder.PreviewPosition
+		size     string
+		width    int
+		height   int
+	}{
+		"right":        {position: fuzzyfinder.PreviewRight, size: "50%"},
+		"left":         {position: fuzzyfinder.PreviewLeft, size: "25"},
+		"top":          {position: fuzzyfinder.PreviewTop, size: "40%"},
+		"bottom":       {position: fuzzyfinder.PreviewBottom, size: "4"},
+		"minimum":      {position: fuzzyfinder.PreviewRight, size: "10%"},
+		"narrow right": {position: fuzzyfinder.PreviewRight, size: "50%", width: 30},
+		"narrow left":  {position: fuzzyfinder.PreviewLeft, size: "50%", width: 30},
+		"too small":    {position: fuzzyfinder.PreviewBottom, size: "50%", height: 5},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			if c.width != 0 {
+				term.SetSize(c.width, 10)
+			}
+			if c.height != 0 {
+				term.SetSize(60, c.height)
+			}
+			events := append(runes("ad"), key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+			term.SetEventsV2(events...)
+
+			assertWithGolden(t, func(t *testing.T) string {
+				_, err := f.Find(
+					tracks,
+					func(i int) string { return tracks[i].Name },
+					fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
+						if i == -1 {
+							return "not found"
+						}
+						return "Name: " + tracks[i].Name + "\nArtist: " + tracks[i].Artist
+					}),
+					fuzzyfinder.WithPreviewLayout(c.position, c.size),
+				)
+				if !errors.Is(err, fuzzyfinder.ErrAbort) {
+					t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+
+	t.Run("invalid size", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := fuzzyfinder.New().Find(
+			[]string{"a"},
+			func(i int) string { return "a" },
+			fuzzyfinder.WithPreviewWindow(func(i, w, h int) string { return "" }),
+			fuzzyfinder.WithPreviewLayout(fuzzyfinder.PreviewTop, "120%"),
+		)
+		if err == nil {
+			t.Error("Find must return an error, but got nil")
+		}
+	})
+}
+
+func TestFind_WithPreviewWindowResult(t *testing.T) {
+	t.Parallel()
+
+	shift := func(k tcell.Key) input { return input{k, rune(k), tcell.ModShift} }
+	wheel := func(x int, b tcell.ButtonMask) tcell.Event { return tcell.NewEventMouse(x, 5, b, tcell.ModNone) }
+	mouse := []fuzzyfinder.Option{fuzzyfinder.WithPreviewMouse()}
+	cases := map[string]struct {
+		events []tcell.Event
+		result fuzzyfinder.PreviewResult
+		opts   []fuzzyfinder.Option
+	}{
+		"initial":            {},
+		"offset":             {result: fuzzyfinder.PreviewResult{Offset: 5}},
+		"center":             {result: fuzzyfinder.PreviewResult{Offset: 10, Center: true}},
+		"offset over end":    {result: fuzzyfinder.PreviewResult{Offset: 100}},
+		"shift-down":         {events: keys(shift(tcell.KeyDown), shift(tcell.KeyDown))},
+		"shift-up":           {events: keys(shift(tcell.KeyUp)), result: fuzzyfinder.PreviewResult{Offset: 5}},
+		"shift-pg-dn":        {events: keys(shift(tcell.KeyPgDn), shift(tcell.KeyPgDn))},
+		"shift-end":          {events: keys(shift(tcell.KeyEnd))},
+		"shift-home":         {events: keys(shift(tcell.KeyHome)), result: fuzzyfinder.PreviewResult{Offset: 5}},
+		"wheel":              {events: []tcell.Event{wheel(40, tcell.WheelDown), wheel(40, tcell.WheelDown), wheel(40, tcell.WheelUp)}, opts: mouse},
+		"wheel on list":      {events: []tcell.Event{wheel(10, tcell.WheelDown)}, opts: mouse},
+		"reset on move":      {events: keys(shift(tcell.KeyDown), input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone})},
+		"reset on move back": {events: keys(shift(tcell.KeyDown), input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}, input{tcell.KeyDown, rune(tcell.KeyDown), tcell.ModNone})},
+		// The wheel is ignored unless WithPreviewMouse is passed.
+		"wheel disabled": {events: []tcell.Event{wheel(40, tcell.WheelDown)}},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			events := append(c.events, key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+			term.SetEventsV2(events...)
+
+			assertWithGolden(t, func(t *testing.T) string {
+				opts := append([]fuzzyfinder.Option{
+					fuzzyfinder.WithPreviewWindowResult(func(i, w, h int) fuzzyfinder.PreviewResult {
+						lines := make([]string, 20)
+						for j := range lines {
+							lines[j] = fmt.Sprintf("%d: %s", j, tracks[i].Artist)
+						}
+						res := c.result
+						res.Text = strings.Join(lines, "\n")
+						return res
+					}),
+				}, c.opts...)
+				_, err := f.Find(tracks, func(i int) string { return tracks[i].Name }, opts...)
+				if !errors.Is(err, fuzzyfinder.ErrAbort) {
+					t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+				}
+				return term.GetResult()
+			})
+		})
+	}
+}
+
+func TestFind_WithAsyncPreviewWindow(t *testing.T) {
+	t.Parallel()
+
+	up := input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}
+	down := input{tcell.KeyDown, rune(tcell.KeyDown), tcell.ModNone}
+	cases := map[string]struct {
+		events []tcell.Event
+		// blocked indicates the preview of the first item doesn't return until
+		// it is cancelled.
+		blocked bool
+		// expectedCalls is the number of calls for each item.
+		expectedCalls map[int]int
+		// cancelled indicates the blocked preview is cancelled before another
+		// preview is computed.
+		cancelled bool
+	}{
+		"normal":   {expectedCalls: map[int]int{0: 1}},
+		"loading":  {blocked: true, expectedCalls: map[int]int{0: 1}},
+		"cancel":   {events: keys(up), blocked: true, expectedCalls: map[int]int{0: 1, 1: 1}, cancelled: true},
+		"cached":   {events: keys(up, down, up), expectedCalls: map[int]int{0: 1, 1: 1}},
+		"no items": {events: runes("foobarbaz"), expectedCalls: map[int]int{0: 1, -1: 1}},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			events := append(c.events, key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+			term.SetEventsV2(events...)
+
+			var (
+				mu         sync.Mutex
+				calls      = map[int]int{}
+				blockedCtx context.Context
+				cancelled  bool
+			)
+			assertWithGolden(t, func(t *testing.T) string {
+				_, err := f.Find(
+					tracks,
+					func(i int) string { return tracks[i].Name },
+					fuzzyfinder.WithAsyncPreviewWindow(func(ctx context.Context, i, w, h int) fuzzyfinder.PreviewResult {
+						mu.Lock()
+						calls[i]++
+						if c.blocked && i == 0 {
+							blockedCtx = ctx
+							mu.Unlock()
+							<-ctx.Done()
+							return fuzzyfinder.PreviewResult{}
+						}
+						if blockedCtx != nil && blockedCtx.Err() != nil {
+							cancelled = true
+						}
+						mu.Unlock()
+						if i == -1 {
+							return fuzzyfinder.PreviewResult{Text: "not found"}
+						}
+						return fuzzyfinder.PreviewResult{Text: "Name: " + tracks[i].Name}
+					}),
+				)
+				if !errors.Is(err, fuzzyfinder.ErrAbort) {
+					t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+				}
+				return term.GetResult()
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if diff := cmp.Diff(c.expectedCalls, calls); diff != "" {
+				t.Errorf("wrong calls: \n%s", diff)
+			}
+			if cancelled != c.cancelled {
+				t.Errorf("expected cancelled: %t, but got %t", c.cancelled, cancelled)
+			}
+		})
+	}
+}
+
+func TestFind_WithPreviewToggle(t *testing.T) {
+	t.Parallel()
+
+	toggle := input{tcell.KeyF2, rune(tcell.KeyF2), tcell.ModNone}
+	cases := map[string]struct {
+		events []tcell.Event
+		opts   []fuzzyfinder.Option
+		// called indicates the preview function is called.
+		called bool
+	}{
+		"hide":        {events: keys(toggle), called: true},
+		"show":        {events: keys(toggle, toggle), called: true},
+		"hidden":      {opts: []fuzzyfinder.Option{fuzzyfinder.WithPreviewHidden()}},
+		"show hidden": {events: keys(toggle), opts: []fuzzyfinder.Option{fuzzyfinder.WithPreviewHidden()}, called: true},
+		"narrow":      {opts: []fuzzyfinder.Option{fuzzyfinder.WithPreviewMinWidth(80)}},
+		"wide":        {opts: []fuzzyfinder.Option{fuzzyfinder.WithPreviewMinWidth(60)}, called: true},
+		// The cursor is kept in the item lines.
+		"bottom": {
+			events: keys(input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}, input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}, input{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone}, toggle),
+			opts:   []fuzzyfinder.Option{fuzzyfinder.WithPreviewHidden(), fuzzyfinder.WithPreviewLayout(fuzzyfinder.PreviewBottom, "5")},
+			called: true,
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, term := fuzzyfinder.NewWithMockedTerminal()
+			events := append(c.events, key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+			term.SetEventsV2(events...)
+
+			var (
+				mu     sync.Mutex
+				called bool
+			)
+			opts := append([]fuzzyfinder.Option{
+				fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
+					mu.Lock()
+					defer mu.Unlock()
+					called = true
+					return "Name: " + tracks[i].Name
+				}),
+				fuzzyfinder.WithPreviewToggle("f2"),
+			}, c.opts...)
+			assertWithGolden(t, func(t *testing.T) string {
+				_, err := f.Find(tracks, func(i int) string { return tracks[i].Name }, opts...)
+				if !errors.Is(err, fuzzyfinder.ErrAbort) {
+					t.Fatalf("Find must return ErrAbort, but got '%s'", err)
+				}
+				return term.GetResult()
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if called != c.called {
+				t.Errorf("expected called: %t, but got %t", c.called, called)
+			}
+		})
+	}
+
+	loader := func(ctx context.Context) ([]string, error) { return nil, nil }
+	invalidCases := map[string][]fuzzyfinder.Option{
+		"invalid key":  {fuzzyfinder.WithPreviewToggle("ctrl-foo")},
+		"reserved key": {fuzzyfinder.WithPreviewToggle("ctrl-p")},
+		"reload key":   {fuzzyfinder.WithPreviewToggle("f2"), fuzzyfinder.WithReload("f2", loader)},
+	}
+	for name, opts := range invalidCases {
+		opts := opts
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts = append(opts, fuzzyfinder.WithPreviewWindow(func(i, w, h int) string { return "" }))
+			_, err := fuzzyfinder.New().Find([]string{"a"}, func(i int) string { return "a" }, opts...)
+			if err == nil {
+				t.Error("Find must return an error, but got nil")
+			}
+		})
+	}
+}
+
+func TestFind_WithItemKey(t *testing.T) {
+	t.Parallel()
+
+	// The user moves the cursor to "b" and an item is inserted at the front.
+	events := keys([]input{
+		{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+		{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+	}...)
+
+	run := func(t *testing.T, find func(f fuzzyfinder.Finder, items *[]string, opts ...fuzzyfinder.Option) ([]int, error)) []int {
+		f, term := fuzzyfinder.NewWithMockedTerminal()
+		term.SetEventsV2(events...)
+
+		var mu sync.RWMutex
+		items := []string{"a", "b", "c"}
+		go func() {
+			time.Sleep(150 * time.Millisecond)
+			mu.Lock()
+			items = append([]string{"x"}, items...)
+			mu.Unlock()
+		}()
+
+		idxs, err := find(
+			f,
+			&items,
+			fuzzyfinder.WithHotReloadLock(mu.RLocker()),
+			fuzzyfinder.WithItemKey(func(i int) string { return items[i] }),
+		)
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		return idxs
+	}
+
+	t.Run("cursor", func(t *testing.T) {
+		t.Parallel()
+
+		idxs := run(t, func(f fuzzyfinder.Finder, items *[]string, opts ...fuzzyfinder.Option) ([]int, error) {
+			idx, err := f.Find(items, func(i int) string { return (*items)[i] }, opts...)
+			return []int{idx}, err
+		})
+		// Tab doesn't work, so the cursor is on "b".
+		if diff := cmp.Diff([]int{2}, idxs); diff != "" {
+			t.Errorf("wrong result: \n%s", diff)
+		}
+	})
+
+	t.Run("selection", func(t *testing.T) {
+		t.Parallel()
+
+		idxs := run(t, func(f fuzzyfinder.Finder, items *[]string, opts ...fuzzyfinder.Option) ([]int, error) {
+			return f.FindMulti(items, func(i int) string { return (*items)[i] }, opts...)
+		})
+		if diff := cmp.Diff([]int{2}, idxs); diff != "" {
+			t.Errorf("wrong result: \n%s", diff)
+		}
+	})
+}
+
 func TestFind_enter(t *testing.T) {
 	t.Parallel()
 
@@ -612,7 +1851,7 @@ func TestFindMulti(t *testing.T) {
 		expected []int
 		abort    bool
 	}{
-		"input glow": {events: runes("glow"), expected: []int{0}},
+		"input glow": {events: runes("glow"), expected: []int{5}},
 		"select two items": {events: keys([]input{
 			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
 			{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
@@ -623,6 +1862,15 @@ func TestFindMulti(t *testing.T) {
 			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
 			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
 		}...), expected: []int{1, 0}},
+		"select three items out of index order": {events: keys([]input{
+			{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+			{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+			{tcell.KeyDown, rune(tcell.KeyDown), tcell.ModNone},
+			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+			{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+		}...), expected: []int{2, 0, 1}},
 		"toggle": {events: keys([]input{
 			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
 			{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
@@ -669,10 +1917,75 @@ func TestFindMulti(t *testing.T) {
 			if n := len(idxs); n != expectedSelectedNum {
 				t.Errorf("expected the number of selected items is %d, but actual %d", expectedSelectedNum, n)
 			}
+			// The items are returned in the order in which they are selected.
+			if diff := cmp.Diff(c.expected, idxs); diff != "" {
+				t.Errorf("wrong selected items: \n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindT(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	events := append(runes("glow"), key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+	term.SetEventsV2(events...)
+
+	tr, err := fuzzyfinder.FindTWithFinder(f, tracks, func(tr *track) string { return tr.Name })
+	if err != nil {
+		t.Fatalf("FindT must not return an error, but got '%s'", err)
+	}
+	if tr != tracks[5] {
+		t.Errorf("expected track: %v, but got %v", tracks[5], tr)
+	}
+
+	t.Run("label is nil", func(t *testing.T) {
+		_, err := fuzzyfinder.FindTWithFinder[string](fuzzyfinder.New(), nil, nil)
+		if err == nil {
+			t.Error("FindT must return an error, but got nil")
+		}
+	})
+
+	loader := func(context.Context) ([]string, error) { return []string{"a", "b", "c"}, nil }
+	cases := map[string]fuzzyfinder.Option{
+		"WithItemSource":    fuzzyfinder.WithItemSource(&testSource{items: []string{"a", "b", "c"}}),
+		"WithReload":        fuzzyfinder.WithReload("ctrl-r", loader),
+		"WithQuerySource":   fuzzyfinder.WithQuerySource(func(ctx context.Context, _ string) ([]string, error) { return loader(ctx) }),
+		"WithCommandSource": fuzzyfinder.WithCommandSource("echo", "a"),
+	}
+	for name, opt := range cases {
+		t.Run(name, func(t *testing.T) {
+			// The options replace the items, so the selected index may be out of
+			// the range of the passed items.
+			_, err := fuzzyfinder.FindTWithFinder(fuzzyfinder.New(), []string{"a"}, func(s string) string { return s }, opt)
+			if err == nil {
+				t.Error("FindT must return an error, but got nil")
+			}
 		})
 	}
 }
 
+func TestFindMultiT(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetEventsV2(keys([]input{
+		{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+		{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+		{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+		{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+	}...)...)
+
+	trs, err := fuzzyfinder.FindMultiTWithFinder(f, tracks, func(tr *track) string { return tr.Name })
+	if err != nil {
+		t.Fatalf("FindMultiT must not return an error, but got '%s'", err)
+	}
+	if diff := cmp.Diff([]*track{tracks[1], tracks[0]}, trs); diff != "" {
+		t.Errorf("wrong result: \n%s", diff)
+	}
+}
+
 func BenchmarkFind(b *testing.B) {
 	b.Run("normal", func(b *testing.B) {
 		b.ReportAllocs()
@@ -0,0 +1,75 @@
+package fuzzyfinder
+
+import (
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ktr0731/go-fuzzyfinder/matching"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// groupHeader returns the name of the group of the i-th matched item if a
+// group header is drawn above it. The header is drawn above the top item of
+// each run of items in the same group, which is the last one in LayoutDefault
+// and the first one in the other layouts.
+func (f *finder) groupHeader(i int) (string, bool) {
+	if f.opt.group == nil {
+		return "", false
+	}
+	name := f.opt.group(f.state.matchedAt(i).Idx)
+	above := i + 1
+	if f.itemsTopDown() {
+		above = i - 1
+	}
+	if above >= 0 && above < f.state.matchedLen() && f.opt.group(f.state.matchedAt(above).Idx) == name {
+		return "", false
+	}
+	return name, true
+}
+
+// groupMatched sorts matched so that the items in the same group are
+// adjacent. The order in each group is kept. If WithRankAcrossGroups is
+// passed, groups are sorted by their best items. Otherwise, they are sorted
+// by their first items.
+func (f *finder) groupMatched(matched []matching.Matched) {
+	type groupedItem struct {
+		m    matching.Matched
+		name string
+	}
+	items := make([]groupedItem, len(matched))
+	ranks := make(map[string]int)
+	for i, m := range matched {
+		name := f.opt.group(m.Idx)
+		items[i] = groupedItem{m: m, name: name}
+
+		rank := m.Idx
+		if f.opt.rankAcrossGroups {
+			rank = i
+		}
+		if r, ok := ranks[name]; !ok || rank < r {
+			ranks[name] = rank
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return ranks[items[i].name] < ranks[items[j].name]
+	})
+	for i, it := range items {
+		matched[i] = it.m
+	}
+}
+
+// drawGroupHeader draws the group header name in row. The row is clipped at
+// maxWidth.
+func (f *finder) drawGroupHeader(name string, row, maxWidth int) {
+	term := f.listPane()
+	style := tcell.StyleDefault.Bold(true).Underline(true)
+	w := 2
+	for _, r := range name {
+		rw := runewidth.RuneWidth(r)
+		if w+rw > maxWidth {
+			break
+		}
+		term.SetContent(w, row, r, nil, style)
+		w += rw
+	}
+}
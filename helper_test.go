@@ -1,6 +1,12 @@
 package fuzzyfinder
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
 
 func New() *finder {
 	return &finder{}
@@ -19,3 +25,59 @@ func NewWithMockedTerminal() (*finder, *TerminalMock) {
 	m.SetSize(w, h)
 	return f, m
 }
+
+func FindTWithFinder[T any](f *finder, items []T, label func(T) string, opts ...Option) (T, error) {
+	res, err := findT(f, items, label, opts)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return res[0], nil
+}
+
+func FindMultiTWithFinder[T any](f *finder, items []T, label func(T) string, opts ...Option) ([]T, error) {
+	return findT(f, items, label, append(opts, withMulti()))
+}
+
+func FindSeq2WithFinder[T any](f *finder, seq iter.Seq2[int, T], label func(int, T) string, opts ...Option) (T, error) {
+	res, err := findSeq2(f, seq, label, opts)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return res[0], nil
+}
+
+// WaitForLines waits until each of lines is displayed as a line of the screen,
+// ignoring the leading and trailing spaces. It reports false if they aren't
+// displayed in 5 seconds.
+func (m *TerminalMock) WaitForLines(lines ...string) bool {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if m.hasLines(lines) {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func (m *TerminalMock) hasLines(lines []string) bool {
+	w, h := m.simScreen.Size()
+	displayed := make(map[string]bool, h)
+	for y := 0; y < h; y++ {
+		var b strings.Builder
+		for x := 0; x < w; x++ {
+			r, _, _, _ := m.simScreen.GetContent(x, y)
+			b.WriteRune(r)
+		}
+		displayed[strings.TrimSpace(b.String())] = true
+	}
+	for _, l := range lines {
+		if !displayed[l] {
+			return false
+		}
+	}
+	return true
+}
+
+type Finder = *finder
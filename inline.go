@@ -0,0 +1,98 @@
+package fuzzyfinder
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2/terminfo"
+	"github.com/pkg/errors"
+)
+
+// minInlineRows is the minimum number of rows of the inline mode, which are
+// the prompt line, the number line and an item line.
+const minInlineRows = 3
+
+// height represents the height passed by WithHeight.
+type height struct {
+	n       int
+	percent bool
+}
+
+// parseHeight parses a height such as "10" or "40%".
+func parseHeight(s string) (height, error) {
+	num, percent := strings.CutSuffix(s, "%")
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 || (percent && n > 100) {
+		return height{}, errors.Errorf("invalid height: %s", s)
+	}
+	return height{n: n, percent: percent}, nil
+}
+
+// rows returns the number of rows in the terminal which has termHeight rows.
+func (h height) rows(termHeight int) int {
+	n := h.n
+	if h.percent {
+		n = termHeight * h.n / 100
+	}
+	return min(max(n, minInlineRows), termHeight)
+}
+
+// inlineTerminfo returns a copy of ti which draws in some rows of the terminal
+// instead of the alternate screen. The rows are set up and released by
+// inlineTty instead of EnterCA and ExitCA because their position is known only
+// when the finder starts. Clearing the screen clears only the rows and the
+// ones below them.
+func inlineTerminfo(ti *terminfo.Terminfo) *terminfo.Terminfo {
+	inline := *ti
+	inline.EnterCA = ""
+	inline.ExitCA = ""
+	inline.Clear = "\x1b[H\x1b[J"
+	return &inline
+}
+
+// inlineRegion returns the first row, starting at 1, of the rows of the inline
+// mode and the number of newlines printed to make them. The rows begin at the
+// cursor, which is at row and col, or at the next row if the cursor is not at
+// the beginning of the line. The terminal is scrolled only if there isn't
+// enough room below the cursor.
+func inlineRegion(row, col, rows, termHeight int) (top, newlines int) {
+	start := row
+	if col > 1 {
+		start++
+	}
+	return min(start, termHeight-rows+1), start - row + rows - 1
+}
+
+// enterInline returns the sequence which makes rows rows from top by printing
+// newlines, and sets them as the scrolling region in the origin mode so that
+// the cursor positions are relative to them.
+func enterInline(top, rows, newlines int) string {
+	return strings.Repeat("\n", newlines) + setInlineRegion(top, rows)
+}
+
+// setInlineRegion returns the sequence which sets rows rows from top as the
+// scrolling region in the origin mode.
+func setInlineRegion(top, rows int) string {
+	return fmt.Sprintf("\x1b[?6l\x1b[%d;%dr\x1b[?6h", top, top+rows-1)
+}
+
+// exitInline returns the sequence which resets the scrolling region and moves
+// the cursor to top, where the finder began.
+func exitInline(top int) string {
+	return fmt.Sprintf("\x1b[?6l\x1b[r\x1b[%d;1H", top)
+}
+
+// parseCursorPosition parses the response to a cursor position request (DSR),
+// which is "ESC [ row ; col R". Bytes before the response are ignored.
+func parseCursorPosition(b []byte) (row, col int, ok bool) {
+	i := bytes.LastIndex(b, []byte("\x1b["))
+	if i == -1 {
+		return 0, 0, false
+	}
+	if _, err := fmt.Sscanf(string(b[i:]), "\x1b[%d;%dR", &row, &col); err != nil {
+		return 0, 0, false
+	}
+	return row, col, true
+}
@@ -0,0 +1,11 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package fuzzyfinder
+
+import "github.com/gdamore/tcell/v2"
+
+// newInlineScreen returns a screen which uses the whole terminal because the
+// inline mode is not supported.
+func newInlineScreen(h height) (tcell.Screen, error) {
+	return tcell.NewScreen()
+}
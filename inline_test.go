@@ -0,0 +1,139 @@
+package fuzzyfinder
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2/terminfo"
+)
+
+func Test_parseHeight(t *testing.T) {
+	cases := map[string]struct {
+		expected height
+		wantErr  bool
+	}{
+		"10":   {expected: height{n: 10}},
+		"40%":  {expected: height{n: 40, percent: true}},
+		"100%": {expected: height{n: 100, percent: true}},
+		"0":    {wantErr: true},
+		"-1":   {wantErr: true},
+		"101%": {wantErr: true},
+		"%":    {wantErr: true},
+		"":     {wantErr: true},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseHeight(name)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("parseHeight must return an error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHeight must not return an error, but got '%s'", err)
+			}
+			if actual != c.expected {
+				t.Errorf("expected %+v, but got %+v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_height_rows(t *testing.T) {
+	cases := map[string]struct {
+		h          height
+		termHeight int
+		expected   int
+	}{
+		"rows":         {h: height{n: 10}, termHeight: 40, expected: 10},
+		"percent":      {h: height{n: 40, percent: true}, termHeight: 40, expected: 16},
+		"minimum":      {h: height{n: 1}, termHeight: 40, expected: minInlineRows},
+		"small term":   {h: height{n: 10}, termHeight: 5, expected: 5},
+		"tiny percent": {h: height{n: 1, percent: true}, termHeight: 40, expected: minInlineRows},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			if actual := c.h.rows(c.termHeight); actual != c.expected {
+				t.Errorf("expected %d, but got %d", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_inlineTerminfo(t *testing.T) {
+	ti := &terminfo.Terminfo{Name: "xterm", EnterCA: "\x1b[?1049h", ExitCA: "\x1b[?1049l", Clear: "\x1b[H\x1b[2J"}
+	actual := inlineTerminfo(ti)
+
+	if actual.EnterCA != "" || actual.ExitCA != "" {
+		t.Errorf("EnterCA and ExitCA must be empty, but got %q and %q", actual.EnterCA, actual.ExitCA)
+	}
+	if expected := "\x1b[H\x1b[J"; actual.Clear != expected {
+		t.Errorf("expected Clear %q, but got %q", expected, actual.Clear)
+	}
+	if ti.EnterCA != "\x1b[?1049h" {
+		t.Error("ti must not be changed")
+	}
+}
+
+func Test_inlineRegion(t *testing.T) {
+	cases := map[string]struct {
+		row, col         int
+		expectedTop      int
+		expectedNewlines int
+	}{
+		// The rows begin at the cursor without scrolling.
+		"top":            {row: 1, col: 1, expectedTop: 1, expectedNewlines: 2},
+		"enough room":    {row: 10, col: 1, expectedTop: 10, expectedNewlines: 2},
+		"just fits":      {row: 22, col: 1, expectedTop: 22, expectedNewlines: 2},
+		"scroll":         {row: 23, col: 1, expectedTop: 22, expectedNewlines: 2},
+		"bottom":         {row: 24, col: 1, expectedTop: 22, expectedNewlines: 2},
+		"middle of line": {row: 10, col: 5, expectedTop: 11, expectedNewlines: 3},
+		"bottom middle":  {row: 24, col: 5, expectedTop: 22, expectedNewlines: 3},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			top, newlines := inlineRegion(c.row, c.col, 3, 24)
+			if top != c.expectedTop || newlines != c.expectedNewlines {
+				t.Errorf("expected top %d and newlines %d, but got %d and %d", c.expectedTop, c.expectedNewlines, top, newlines)
+			}
+		})
+	}
+}
+
+func Test_inlineSequences(t *testing.T) {
+	if expected, actual := "\n\n\x1b[?6l\x1b[10;12r\x1b[?6h", enterInline(10, 3, 2); actual != expected {
+		t.Errorf("expected %q, but got %q", expected, actual)
+	}
+	if expected, actual := "\x1b[?6l\x1b[r\x1b[10;1H", exitInline(10); actual != expected {
+		t.Errorf("expected %q, but got %q", expected, actual)
+	}
+}
+
+func Test_parseCursorPosition(t *testing.T) {
+	cases := map[string]struct {
+		in       string
+		row, col int
+		ok       bool
+	}{
+		"normal":       {in: "\x1b[12;5R", row: 12, col: 5, ok: true},
+		"input before": {in: "ab\x1b[3;1R", row: 3, col: 1, ok: true},
+		"invalid":      {in: "\x1b[12R"},
+		"empty":        {in: ""},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			row, col, ok := parseCursorPosition([]byte(c.in))
+			if row != c.row || col != c.col || ok != c.ok {
+				t.Errorf("expected (%d, %d, %t), but got (%d, %d, %t)", c.row, c.col, c.ok, row, col, ok)
+			}
+		})
+	}
+}
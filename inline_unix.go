@@ -0,0 +1,134 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos
+
+package fuzzyfinder
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
+)
+
+// cursorPositionTimeout is the time to wait for the response to a cursor
+// position request.
+const cursorPositionTimeout = 500 * time.Millisecond
+
+// inlineTty is a tty which has only the rows used by the inline mode. The rows
+// begin at the cursor when the finder starts, and they are made again in the
+// new size when the terminal is resized.
+type inlineTty struct {
+	tcell.Tty
+	h height
+
+	// started indicates the rows are made. termHeight is the height of the
+	// terminal, and top and rows are the first row, starting at 1, and the
+	// number of the rows.
+	started               bool
+	termHeight, top, rows int
+}
+
+func (t *inlineTty) Start() error {
+	if err := t.Tty.Start(); err != nil {
+		return err
+	}
+	_, termHeight, err := t.Tty.WindowSize()
+	if err != nil {
+		return err
+	}
+	row, col, ok := queryCursorPosition()
+	if !ok {
+		// Assume the cursor is at the bottom if the terminal doesn't respond.
+		row, col = termHeight, 1
+	}
+
+	rows := t.h.rows(termHeight)
+	top, newlines := inlineRegion(row, col, rows, termHeight)
+	if _, err := io.WriteString(t.Tty, enterInline(top, rows, newlines)); err != nil {
+		return err
+	}
+	t.started = true
+	t.termHeight, t.top, t.rows = termHeight, top, rows
+	return nil
+}
+
+func (t *inlineTty) Stop() error {
+	if t.started {
+		t.started = false
+		if _, err := io.WriteString(t.Tty, exitInline(t.top)); err != nil {
+			return err
+		}
+	}
+	return t.Tty.Stop()
+}
+
+// WindowSize returns the size of the rows. If the terminal is resized, the
+// rows are made again in the new size. tcell calls it whenever it draws the
+// screen, with the screen locked.
+func (t *inlineTty) WindowSize() (int, int, error) {
+	width, termHeight, err := t.Tty.WindowSize()
+	if err != nil {
+		return 0, 0, err
+	}
+	if !t.started {
+		return width, t.h.rows(termHeight), nil
+	}
+	if termHeight != t.termHeight {
+		t.termHeight = termHeight
+		t.rows = t.h.rows(termHeight)
+		// Keep the rows at the same position unless they don't fit.
+		t.top = max(min(t.top, termHeight-t.rows+1), 1)
+		// The old rows are cleared, and tcell redraws the screen.
+		if _, err := io.WriteString(t.Tty, setInlineRegion(t.top, t.rows)+"\x1b[H\x1b[J"); err != nil {
+			return 0, 0, err
+		}
+	}
+	return width, t.rows, nil
+}
+
+// queryCursorPosition asks the terminal the position of the cursor. The
+// terminal must be in raw mode. It reports false if the terminal doesn't
+// respond in time, or the response can't be read with a timeout.
+func queryCursorPosition() (row, col int, ok bool) {
+	// Another file is opened so that the read deadline doesn't affect tcell.
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer tty.Close()
+	if err := tty.SetReadDeadline(time.Now().Add(cursorPositionTimeout)); err != nil {
+		// A blocked read can't be stopped, and it would steal the input of
+		// tcell.
+		return 0, 0, false
+	}
+	if _, err := tty.WriteString("\x1b[6n"); err != nil {
+		return 0, 0, false
+	}
+
+	var buf []byte
+	b := make([]byte, 32)
+	for !bytes.HasSuffix(buf, []byte("R")) {
+		n, err := tty.Read(b)
+		if err != nil {
+			return 0, 0, false
+		}
+		buf = append(buf, b[:n]...)
+	}
+	return parseCursorPosition(buf)
+}
+
+// newInlineScreen returns a screen which draws in the rows of the terminal
+// specified by h below the cursor.
+func newInlineScreen(h height) (tcell.Screen, error) {
+	ti, err := tcell.LookupTerminfo(os.Getenv("TERM"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to look up terminfo")
+	}
+	tty, err := tcell.NewDevTty()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open tty")
+	}
+	return tcell.NewTerminfoScreenFromTtyTerminfo(&inlineTty{Tty: tty, h: h}, inlineTerminfo(ti))
+}
@@ -0,0 +1,96 @@
+package fuzzyfinder
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// itemList holds item strings in one contiguous byte arena.
+//
+// An item costs its length in bytes plus an 8-byte end offset, instead of a
+// 16-byte string header and a separate allocation for each item. For example,
+// one million items of 40 bytes take about 48MB.
+//
+// Bytes written to the arena are never modified, so strings returned by at
+// share the memory of the arena without copying. Removing or updating items
+// builds a new arena instead.
+type itemList struct {
+	arena []byte
+	// ends holds the end offset of each item in arena. The i-th item is
+	// arena[ends[i-1]:ends[i]].
+	ends []int
+}
+
+func newItemList(items []string) itemList {
+	var size int
+	for _, s := range items {
+		size += len(s)
+	}
+	l := itemList{
+		arena: make([]byte, 0, size),
+		ends:  make([]int, 0, len(items)),
+	}
+	l.append(items...)
+	return l
+}
+
+// len returns the number of items.
+func (l *itemList) len() int {
+	return len(l.ends)
+}
+
+// at returns the i-th item. The returned string shares the memory of the
+// arena.
+func (l *itemList) at(i int) string {
+	var start int
+	if i > 0 {
+		start = l.ends[i-1]
+	}
+	end := l.ends[i]
+	if start == end {
+		return ""
+	}
+	return unsafe.String(&l.arena[start], end-start)
+}
+
+// append appends items to the end.
+func (l *itemList) append(items ...string) {
+	for _, s := range items {
+		l.arena = append(l.arena, s...)
+		l.ends = append(l.ends, len(l.arena))
+	}
+}
+
+// remove removes the items in [from, to).
+func (l *itemList) remove(from, to int) {
+	l.rebuild(from, to, nil)
+}
+
+// update replaces the items starting at from with items.
+func (l *itemList) update(from int, items []string) {
+	l.rebuild(from, from+len(items), items)
+}
+
+// rebuild builds a new arena in which the items in [from, to) are replaced
+// with items.
+func (l *itemList) rebuild(from, to int, items []string) {
+	n := l.len()
+	res := itemList{
+		arena: make([]byte, 0, len(l.arena)),
+		ends:  make([]int, 0, n-(to-from)+len(items)),
+	}
+	for i := 0; i < from; i++ {
+		res.append(l.at(i))
+	}
+	res.append(items...)
+	for i := to; i < n; i++ {
+		res.append(l.at(i))
+	}
+	*l = res
+}
+
+// clone returns a copy of the i-th item which doesn't share the memory of the
+// arena, so that a returned item doesn't keep the whole arena alive.
+func (l *itemList) clone(i int) string {
+	return strings.Clone(l.at(i))
+}
@@ -0,0 +1,109 @@
+package fuzzyfinder
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_itemList(t *testing.T) {
+	l := newItemList([]string{"foo", "", "bar"})
+	l.append("baz", "qux")
+	l.remove(0, 1)
+	l.update(2, []string{"quux"})
+
+	var actual []string
+	for i := 0; i < l.len(); i++ {
+		actual = append(actual, l.at(i))
+	}
+	if diff := cmp.Diff([]string{"", "bar", "quux", "qux"}, actual); diff != "" {
+		t.Errorf("wrong items: \n%s", diff)
+	}
+}
+
+// benchItems returns n items of 40 bytes.
+func benchItems(n int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf("%040d", i)
+	}
+	return items
+}
+
+// bytesPerItem returns the heap bytes retained by the value built by build per
+// item.
+func bytesPerItem(n int, build func() interface{}) float64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	v := build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+	return float64(after.HeapAlloc-before.HeapAlloc) / float64(n)
+}
+
+func Benchmark_itemList(b *testing.B) {
+	for _, n := range []int{100_000, 1_000_000} {
+		items := benchItems(n)
+
+		b.Run(fmt.Sprintf("arena/%d", n), func(b *testing.B) {
+			build := func() interface{} {
+				var l itemList
+				for _, s := range items {
+					l.append(s)
+				}
+				return l
+			}
+			for i := 0; i < b.N; i++ {
+				build()
+			}
+			b.StopTimer()
+			b.ReportMetric(bytesPerItem(n, build), "B/item")
+		})
+
+		// strings is the previous representation, which holds a string and a
+		// Matched for each item.
+		b.Run(fmt.Sprintf("strings/%d", n), func(b *testing.B) {
+			build := func() interface{} {
+				var (
+					l       []string
+					matched []struct{ idx, from, to, score int }
+				)
+				for j, s := range items {
+					l = append(l, strings.Clone(s))
+					matched = append(matched, struct{ idx, from, to, score int }{idx: j})
+				}
+				return []interface{}{l, matched}
+			}
+			for i := 0; i < b.N; i++ {
+				build()
+			}
+			b.StopTimer()
+			b.ReportMetric(bytesPerItem(n, build), "B/item")
+		})
+	}
+}
+
+func Benchmark_filter(b *testing.B) {
+	const n = 1_000_000
+	items := newItemList(benchItems(n))
+
+	for _, input := range []string{"", "999"} {
+		b.Run(fmt.Sprintf("query=%q", input), func(b *testing.B) {
+			opt := defaultOption
+			f := &finder{
+				opt:   &opt,
+				state: state{items: items, input: []rune(input)},
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				f.filter()
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package fuzzyfinder
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
+)
+
+// keyBinding represents a key specified by a key name such as "ctrl-r".
+type keyBinding struct {
+	key tcell.Key
+	// r is the rune of the key if key is tcell.KeyRune.
+	r   rune
+	alt bool
+}
+
+var namedKeys = map[string]tcell.Key{
+	"enter":  tcell.KeyEnter,
+	"tab":    tcell.KeyTab,
+	"btab":   tcell.KeyBacktab,
+	"up":     tcell.KeyUp,
+	"down":   tcell.KeyDown,
+	"left":   tcell.KeyLeft,
+	"right":  tcell.KeyRight,
+	"home":   tcell.KeyHome,
+	"end":    tcell.KeyEnd,
+	"pgup":   tcell.KeyPgUp,
+	"pgdn":   tcell.KeyPgDn,
+	"insert": tcell.KeyInsert,
+	"delete": tcell.KeyDelete,
+	"space":  tcell.KeyRune,
+}
+
+// parseKey parses a key name. The available names are "ctrl-a" to "ctrl-z",
+// "f1" to "f12", "enter", "tab", "btab", "up", "down", "left", "right",
+// "home", "end", "pgup", "pgdn", "insert", "delete", "space" and a single
+// character. "alt-" can be prepended to a single character.
+func parseKey(name string) (keyBinding, error) {
+	s := strings.ToLower(name)
+
+	if c, ok := strings.CutPrefix(s, "alt-"); ok {
+		b, err := parseKey(c)
+		if err != nil || b.key != tcell.KeyRune {
+			return keyBinding{}, errors.Errorf("invalid key name: %s", name)
+		}
+		b.alt = true
+		return b, nil
+	}
+
+	if c, ok := strings.CutPrefix(s, "ctrl-"); ok && len(c) == 1 && 'a' <= c[0] && c[0] <= 'z' {
+		return keyBinding{key: tcell.KeyCtrlA + tcell.Key(c[0]-'a')}, nil
+	}
+
+	if c, ok := strings.CutPrefix(s, "f"); ok && c != "" {
+		if n, err := strconv.Atoi(c); err == nil && 1 <= n && n <= 12 {
+			return keyBinding{key: tcell.KeyF1 + tcell.Key(n-1)}, nil
+		}
+	}
+
+	if k, ok := namedKeys[s]; ok {
+		if s == "space" {
+			return keyBinding{key: tcell.KeyRune, r: ' '}, nil
+		}
+		return keyBinding{key: k}, nil
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return keyBinding{key: tcell.KeyRune, r: r}, nil
+	}
+
+	return keyBinding{}, errors.Errorf("invalid key name: %s", name)
+}
+
+// reservedKeys are the keys bound by the finder itself. Some names are the
+// same keys as others: ctrl-h is backspace, ctrl-i is tab and ctrl-m is enter.
+var reservedKeys = map[tcell.Key]string{
+	tcell.KeyCtrlC:     "aborting",
+	tcell.KeyCtrlD:     "aborting",
+	tcell.KeyBackspace: "deleting a character",
+	tcell.KeyDelete:    "deleting a character",
+	tcell.KeyCtrlW:     "deleting a word",
+	tcell.KeyCtrlU:     "clearing the query",
+	tcell.KeyEnter:     "accepting",
+	tcell.KeyTab:       "selecting",
+	tcell.KeyLeft:      "moving the cursor left",
+	tcell.KeyCtrlB:     "moving the cursor left",
+	tcell.KeyRight:     "moving the cursor right",
+	tcell.KeyCtrlF:     "moving the cursor right",
+	tcell.KeyHome:      "moving the cursor to the beginning",
+	tcell.KeyCtrlA:     "moving the cursor to the beginning",
+	tcell.KeyEnd:       "moving the cursor to the end",
+	tcell.KeyCtrlE:     "moving the cursor to the end",
+	tcell.KeyUp:        "moving the cursor up",
+	tcell.KeyCtrlK:     "moving the cursor up",
+	tcell.KeyCtrlP:     "moving the cursor up",
+	tcell.KeyDown:      "moving the cursor down",
+	tcell.KeyCtrlJ:     "moving the cursor down",
+	tcell.KeyCtrlN:     "moving the cursor down",
+	tcell.KeyPgUp:      "scrolling up",
+	tcell.KeyPgDn:      "scrolling down",
+	tcell.KeyCtrlT:     "resuming following",
+}
+
+// parseBindableKey is nearly the same as parseKey, but it returns an error if
+// the key is reserved by the finder or is a character typed into the query.
+func parseBindableKey(name string) (keyBinding, error) {
+	b, err := parseKey(name)
+	if err != nil {
+		return keyBinding{}, err
+	}
+	if b.key == tcell.KeyRune && !b.alt {
+		return keyBinding{}, errors.Errorf("%s is typed into the query", name)
+	}
+	if action, ok := reservedKeys[b.key]; ok {
+		return keyBinding{}, errors.Errorf("%s is reserved for %s", name, action)
+	}
+	return b, nil
+}
+
+// match reports whether e is the key.
+func (b keyBinding) match(e *tcell.EventKey) bool {
+	if e.Key() != b.key {
+		return false
+	}
+	if b.key != tcell.KeyRune {
+		return true
+	}
+	return e.Rune() == b.r && (e.Modifiers()&tcell.ModAlt != 0) == b.alt
+}
@@ -0,0 +1,83 @@
+package fuzzyfinder
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_parseKey(t *testing.T) {
+	cases := map[string]struct {
+		expected keyBinding
+		wantErr  bool
+	}{
+		"ctrl-r":   {expected: keyBinding{key: tcell.KeyCtrlR}},
+		"CTRL-R":   {expected: keyBinding{key: tcell.KeyCtrlR}},
+		"f5":       {expected: keyBinding{key: tcell.KeyF5}},
+		"pgup":     {expected: keyBinding{key: tcell.KeyPgUp}},
+		"space":    {expected: keyBinding{key: tcell.KeyRune, r: ' '}},
+		"alt-r":    {expected: keyBinding{key: tcell.KeyRune, r: 'r', alt: true}},
+		"R":        {expected: keyBinding{key: tcell.KeyRune, r: 'R'}},
+		"ctrl-foo": {wantErr: true},
+		"f13":      {wantErr: true},
+		"alt-up":   {wantErr: true},
+		"":         {wantErr: true},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseKey(name)
+			if c.wantErr {
+				if err == nil {
+					t.Errorf("parseKey must return an error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseKey must not return an error, but got '%s'", err)
+			}
+			if actual != c.expected {
+				t.Errorf("expected %+v, but got %+v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_parseBindableKey(t *testing.T) {
+	cases := map[string]bool{
+		"ctrl-r": false,
+		"f2":     false,
+		"alt-r":  false,
+		"ctrl-p": true,
+		"ctrl-m": true, // enter
+		"ctrl-i": true, // tab
+		"ctrl-t": true,
+		"pgup":   true,
+		"r":      true,
+		"space":  true,
+	}
+
+	for name, wantErr := range cases {
+		wantErr := wantErr
+		t.Run(name, func(t *testing.T) {
+			_, err := parseBindableKey(name)
+			if wantErr && err == nil {
+				t.Errorf("parseBindableKey must return an error, but got nil")
+			}
+			if !wantErr && err != nil {
+				t.Errorf("parseBindableKey must not return an error, but got '%s'", err)
+			}
+		})
+	}
+}
+
+func Test_keyBinding_match(t *testing.T) {
+	b := keyBinding{key: tcell.KeyRune, r: 'r', alt: true}
+	if !b.match(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModAlt)) {
+		t.Error("alt-r must match")
+	}
+	if b.match(tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone)) {
+		t.Error("r must not match")
+	}
+}
@@ -0,0 +1,59 @@
+package fuzzyfinder
+
+import "github.com/gdamore/tcell/v2"
+
+// Layout represents the arrangement of the prompt line and the item lines.
+type Layout int
+
+const (
+	// LayoutDefault displays the prompt line at the bottom and the items
+	// from the bottom to the top. It is the default layout.
+	LayoutDefault Layout = iota
+	// LayoutReverse displays the prompt line at the top and the items from
+	// the top to the bottom.
+	LayoutReverse
+	// LayoutReverseList displays the prompt line at the bottom and the items
+	// from the top to the bottom.
+	LayoutReverseList
+)
+
+// promptOnTop reports whether the prompt line and the other lines around it
+// are displayed at the top.
+func (f *finder) promptOnTop() bool {
+	return f.opt.layout == LayoutReverse
+}
+
+// itemsTopDown reports whether the matched items are displayed from the top,
+// which means the first matched item is the top one.
+func (f *finder) itemsTopDown() bool {
+	return f.opt.layout != LayoutDefault
+}
+
+// layoutKey returns the key which has the same meaning in LayoutDefault as k
+// has in the current layout. In the layouts which display the items from the
+// top, the keys moving the cursor up and down are swapped so that they move
+// it in the same direction on the screen.
+func (f *finder) layoutKey(k tcell.Key) tcell.Key {
+	if !f.itemsTopDown() {
+		return k
+	}
+	switch k {
+	case tcell.KeyUp:
+		return tcell.KeyDown
+	case tcell.KeyDown:
+		return tcell.KeyUp
+	case tcell.KeyCtrlK:
+		return tcell.KeyCtrlJ
+	case tcell.KeyCtrlJ:
+		return tcell.KeyCtrlK
+	case tcell.KeyCtrlP:
+		return tcell.KeyCtrlN
+	case tcell.KeyCtrlN:
+		return tcell.KeyCtrlP
+	case tcell.KeyPgUp:
+		return tcell.KeyPgDn
+	case tcell.KeyPgDn:
+		return tcell.KeyPgUp
+	}
+	return k
+}
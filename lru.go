@@ -0,0 +1,48 @@
+package fuzzyfinder
+
+import "container/list"
+
+// lru is a cache which holds up to size recently used values. It is not safe
+// for concurrent use.
+type lru[K comparable, V any] struct {
+	size int
+	// order holds the entries. The front is the most recently used one.
+	order *list.List
+	elems map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{size: size, order: list.New(), elems: map[K]*list.Element{}}
+}
+
+// get returns the value of k and marks it as the most recently used one.
+func (c *lru[K, V]) get(k K) (V, bool) {
+	e, ok := c.elems[k]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry[K, V]).value, true
+}
+
+// add adds v as the value of k. If the cache is full, the least recently used
+// value is removed.
+func (c *lru[K, V]) add(k K, v V) {
+	if e, ok := c.elems[k]; ok {
+		e.Value.(*lruEntry[K, V]).value = v
+		c.order.MoveToFront(e)
+		return
+	}
+	c.elems[k] = c.order.PushFront(&lruEntry[K, V]{key: k, value: v})
+	for c.order.Len() > c.size {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.elems, e.Value.(*lruEntry[K, V]).key)
+	}
+}
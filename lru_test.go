@@ -0,0 +1,31 @@
+package fuzzyfinder
+
+import "testing"
+
+func Test_lru(t *testing.T) {
+	c := newLRU[int, string](2)
+	c.add(1, "a")
+	c.add(2, "b")
+	if _, ok := c.get(1); !ok {
+		t.Fatal("1 must be cached")
+	}
+	// 2 is the least recently used one.
+	c.add(3, "c")
+
+	if _, ok := c.get(2); ok {
+		t.Error("2 must be removed")
+	}
+	for k, expected := range map[int]string{1: "a", 3: "c"} {
+		if v, ok := c.get(k); !ok || v != expected {
+			t.Errorf("expected %s for %d, but got %s (%t)", expected, k, v, ok)
+		}
+	}
+
+	c.add(1, "A")
+	if v, _ := c.get(1); v != "A" {
+		t.Errorf("expected A, but got %s", v)
+	}
+	if n := c.order.Len(); n != 2 {
+		t.Errorf("expected 2 entries, but got %d", n)
+	}
+}
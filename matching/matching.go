@@ -49,22 +49,50 @@ func WithMode(m Mode) Option {
 // FindAll tries to find out sub-strings from slice that match the passed argument in.
 // The returned slice is sorted by similarity scores in descending order.
 func FindAll(in string, slice []string, opts ...Option) []Matched {
+	return FindAllFunc(in, len(slice), func(i int) string { return slice[i] }, opts...)
+}
+
+// FindAllFunc is the same as FindAll, but it reads n strings by calling item
+// with each index instead of reading a slice.
+func FindAllFunc(in string, n int, item func(i int) string, opts ...Option) []Matched {
 	var opt opt
 	for _, o := range opts {
 		o(&opt)
 	}
-	m := match(in, slice, opt)
+	m := match(in, n, item, opt)
 	sort.Slice(m, func(i, j int) bool {
-		if m[i].score == m[j].score {
-			return m[i].Idx > m[j].Idx
-		}
-		return m[i].score > m[j].score
+		return less(m[i], m[j])
 	})
 	return m
 }
 
-// match iterates each string of slice for check whether it is matched to the input string.
-func match(input string, slice []string, opt opt) (res []Matched) {
+// Merge merges two results of FindAll into one result which is sorted in the
+// same order as FindAll. Each Idx must be unique across a and b.
+func Merge(a, b []Matched) []Matched {
+	res := make([]Matched, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if less(b[0], a[0]) {
+			res = append(res, b[0])
+			b = b[1:]
+		} else {
+			res = append(res, a[0])
+			a = a[1:]
+		}
+	}
+	res = append(res, a...)
+	return append(res, b...)
+}
+
+// less reports whether x should be placed before y.
+func less(x, y Matched) bool {
+	if x.score == y.score {
+		return x.Idx > y.Idx
+	}
+	return x.score > y.score
+}
+
+// match iterates each string for check whether it is matched to the input string.
+func match(input string, n int, item func(i int) string, opt opt) (res []Matched) {
 	if opt.mode == ModeSmart {
 		// Find an upper-case rune
 		n := strings.IndexFunc(input, unicode.IsUpper)
@@ -77,7 +105,8 @@ func match(input string, slice []string, opt opt) (res []Matched) {
 	}
 
 	in := []rune(input)
-	for idxOfSlice, s := range slice {
+	for idxOfSlice := 0; idxOfSlice < n; idxOfSlice++ {
+		s := item(idxOfSlice)
 		var idx int
 		if opt.mode == ModeCaseInsensitive {
 			s = strings.ToLower(s)
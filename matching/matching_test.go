@@ -66,6 +66,35 @@ func TestMatch(t *testing.T) {
 	}
 }
 
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	slice := []string{
+		"WHITE ALBUM",
+		"SOUND OF DESTINY",
+		"Twinkle Snow",
+		"White Snow",
+		"Snow halation",
+	}
+	expected := matching.FindAll("snow", slice)
+
+	a := matching.FindAll("snow", slice[:2])
+	b := matching.FindAll("snow", slice[2:])
+	for i := range b {
+		b[i].Idx += 2
+	}
+	actual := matching.Merge(a, b)
+
+	if len(actual) != len(expected) {
+		t.Fatalf("the result length must be %d, but got %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		if actual[i].Idx != expected[i].Idx {
+			t.Errorf("actual[%d].Idx must be equal to %d, but got %d", i, expected[i].Idx, actual[i].Idx)
+		}
+	}
+}
+
 func BenchmarkMatch(b *testing.B) {
 	benchSlice := []string{
 		"Lorem ipsum dolor sit amet, consectetuer adipiscing elit",
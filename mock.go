@@ -72,6 +72,9 @@ func (m *TerminalMock) SetEventsV2(events ...tcell.Event) {
 		case *tcell.EventKey:
 			ek := event
 			m.simScreen.InjectKey(ek.Key(), ek.Rune(), ek.Modifiers())
+		case *tcell.EventMouse:
+			x, y := event.Position()
+			m.simScreen.InjectMouse(x, y, event.Buttons(), event.Modifiers())
 		case *tcell.EventResize:
 			er := event
 			w, h := er.Size()
@@ -0,0 +1,103 @@
+package fuzzyfinder
+
+import "strings"
+
+// maxItemLines is the maximum number of rows of an item if WithMultiLineItems
+// is passed.
+const maxItemLines = 5
+
+// itemHeight returns the number of rows of the item. It is always 1 unless
+// WithMultiLineItems is passed. The number is limited to maxRows.
+func (f *finder) itemHeight(idx, maxRows int) int {
+	if !f.opt.multiLine {
+		return 1
+	}
+	n := strings.Count(f.state.items.at(idx), "\n") + 1
+	return max(min(n, maxItemLines, maxRows), 1)
+}
+
+// itemAreaRows returns the number of rows in which items are drawn.
+func (f *finder) itemAreaRows() int {
+	_, height := f.listPane().Size()
+	// The prompt line and the number line.
+	rows := height - 2
+	if len(f.headerLine()) > 0 {
+		rows--
+	}
+	if f.state.errMsg != "" {
+		rows--
+	}
+	if f.opt.tableColumns != nil {
+		// The column header row.
+		rows--
+	}
+	return max(rows, 0)
+}
+
+// variableRows reports whether a matched item may take more than one row.
+func (f *finder) variableRows() bool {
+	return f.opt.multiLine || f.opt.group != nil
+}
+
+// matchedHeight returns the number of rows of the i-th matched item including
+// its group header. The number is limited to maxRows.
+func (f *finder) matchedHeight(i, maxRows int) int {
+	if _, ok := f.groupHeader(i); ok {
+		return f.itemHeight(f.state.matchedAt(i).Idx, max(maxRows-1, 1)) + 1
+	}
+	return f.itemHeight(f.state.matchedAt(i).Idx, maxRows)
+}
+
+// fitCursor scrolls the item lines so that the whole item pointed by the
+// cursor is displayed. It does nothing unless WithMultiLineItems, WithGroups
+// or WithTable is passed.
+func (f *finder) fitCursor() {
+	if (!f.variableRows() && f.opt.tableColumns == nil) || f.state.matchedLen() == 0 {
+		return
+	}
+	rows := f.itemAreaRows()
+	if !f.variableRows() {
+		// The key bindings don't know the column header row.
+		f.state.cursorY = max(min(f.state.cursorY, rows-1), 0)
+		return
+	}
+	for f.state.cursorY > 0 && f.matchedRows(f.state.y-f.state.cursorY, f.state.y, rows) > rows {
+		f.state.cursorY--
+	}
+}
+
+// matchedRows returns the number of rows of the matched items in [from, to].
+func (f *finder) matchedRows(from, to, maxRows int) int {
+	var n int
+	for i := from; i <= to; i++ {
+		n += f.matchedHeight(i, maxRows)
+	}
+	return n
+}
+
+// pageItems returns the number of items by which PgUp or PgDn moves the
+// cursor. If items may take more than one row, it is the number of items which
+// fit in the item lines next to the cursor in the direction, instead of
+// pageScrollBy.
+func (f *finder) pageItems(up bool, pageScrollBy int) int {
+	if !f.variableRows() {
+		return pageScrollBy
+	}
+	rows := f.itemAreaRows()
+	var n, sum int
+	for i := f.state.y; ; n++ {
+		if up {
+			i++
+		} else {
+			i--
+		}
+		if i < 0 || i >= f.state.matchedLen() {
+			break
+		}
+		sum += f.matchedHeight(i, rows)
+		if sum > rows {
+			break
+		}
+	}
+	return max(n, 1)
+}
@@ -0,0 +1,220 @@
+package fuzzyfinder
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ktr0731/go-fuzzyfinder/matching"
+)
+
+// breadcrumbSeparator separates the levels in the breadcrumb of FindNested.
+const breadcrumbSeparator = " › "
+
+// level holds the state of a list which the user drilled down from by
+// FindNested.
+type level struct {
+	items, rawItems itemList
+	keys            []string
+	matched         []matching.Matched
+	allMatched      bool
+
+	input      []rune
+	x, cursorX int
+	y, cursorY int
+	// idx is the index of the accepted item.
+	idx int
+}
+
+// path returns the indices of the accepted items in the levels.
+func (f *finder) path() []int {
+	path := make([]int, len(f.state.levels))
+	for i, l := range f.state.levels {
+		path[i] = l.idx
+	}
+	return path
+}
+
+// drillDown loads the children of the item pointed by the cursor in the
+// background, and a spinner is displayed in the number line while they are
+// loaded. It reports whether loading is started. It must be called while
+// stateMu is locked.
+//
+// If the item has children, they replace the items, and the current list is
+// saved as a level. If it has no children, the item is accepted. An error of
+// the children function is displayed in the header area.
+func (f *finder) drillDown(ctx context.Context) bool {
+	if f.opt.children == nil || f.state.matchedLen() == 0 {
+		return false
+	}
+	idx := f.state.matchedAt(f.state.y).Idx
+	path := append(f.path(), idx)
+
+	f.cancelLoader()
+	ctx, cancel := context.WithCancel(ctx)
+	f.cancelReload = cancel
+	gen := f.state.reloadGen
+	f.state.reloading = true
+	f.state.drilling = true
+	f.state.errMsg = ""
+
+	go func() {
+		defer cancel()
+
+		done := make(chan struct{})
+		defer close(done)
+		go f.animateSpinner(done)
+
+		items, err := f.opt.children(ctx, path)
+
+		f.stateMu.Lock()
+		if gen != f.state.reloadGen || ctx.Err() != nil {
+			// Loading is cancelled, or the finder is closed.
+			f.stateMu.Unlock()
+			return
+		}
+		f.state.reloading = false
+		f.state.drilling = false
+		switch {
+		case err != nil:
+			f.state.errMsg = err.Error()
+		case len(items) == 0:
+			f.stateMu.Unlock()
+			// The keys are ignored while loading, so the cursor is still on
+			// the item.
+			select {
+			case f.acceptCh <- struct{}{}:
+			default:
+			}
+			return
+		default:
+			f.pushLevel(idx, items)
+		}
+		f.stateMu.Unlock()
+		f.requestFilter()
+	}()
+	return true
+}
+
+// drillingKey handles e while the children are loaded by drillDown. Esc and
+// Backspace cancel loading, and the other keys are ignored so that the cursor
+// stays on the item. It reports whether e is handled.
+func (f *finder) drillingKey(e *tcell.EventKey) bool {
+	if !f.state.drilling {
+		return false
+	}
+	switch e.Key() {
+	case tcell.KeyCtrlC, tcell.KeyCtrlD:
+		return false
+	case tcell.KeyEsc, tcell.KeyBackspace, tcell.KeyBackspace2:
+		f.cancelLoader()
+	}
+	return true
+}
+
+// pushLevel replaces the items with the children of the item idx. The current
+// list is saved as a level.
+func (f *finder) pushLevel(idx int, items []string) {
+	f.state.levels = append(f.state.levels, level{
+		items:      f.state.items,
+		rawItems:   f.state.rawItems,
+		keys:       f.state.keys,
+		matched:    f.state.matched,
+		allMatched: f.state.allMatched,
+		input:      f.state.input,
+		x:          f.state.x,
+		cursorX:    f.state.cursorX,
+		y:          f.state.y,
+		cursorY:    f.state.cursorY,
+		idx:        idx,
+	})
+	var raw []string
+	if f.opt.ansi {
+		raw = items
+		items = stripANSIAll(items)
+	}
+	f.state.items = newItemList(items)
+	f.state.rawItems = newItemList(raw)
+	f.state.keys = nil
+	f.state.matchAll()
+	f.state.input = nil
+	f.state.x, f.state.cursorX = 0, 0
+	f.state.y, f.state.cursorY = 0, 0
+	f.state.version++
+}
+
+// drillUp restores the list which the user drilled down from with its query
+// and cursor. It reports whether the list is restored.
+func (f *finder) drillUp() bool {
+	if len(f.state.levels) == 0 {
+		return false
+	}
+	l := f.state.levels[len(f.state.levels)-1]
+	f.state.levels = f.state.levels[:len(f.state.levels)-1]
+
+	f.state.items = l.items
+	f.state.rawItems = l.rawItems
+	f.state.keys = l.keys
+	f.state.matched = l.matched
+	f.state.allMatched = l.allMatched
+	f.state.input = l.input
+	f.state.x, f.state.cursorX = l.x, l.cursorX
+	f.state.y, f.state.cursorY = l.y, l.cursorY
+	f.state.errMsg = ""
+	f.state.version++
+	return true
+}
+
+// headerLine returns the text of the header line. It is the header passed by
+// WithHeader followed by the breadcrumb of FindNested.
+func (f *finder) headerLine() string {
+	if len(f.state.levels) == 0 {
+		return f.opt.header
+	}
+	labels := make([]string, 0, len(f.state.levels)+1)
+	if f.opt.header != "" {
+		labels = append(labels, f.opt.header)
+	}
+	for _, l := range f.state.levels {
+		labels = append(labels, l.items.at(l.idx))
+	}
+	return strings.Join(labels, breadcrumbSeparator)
+}
+
+func (f *finder) findNested(items []string, children func(ctx context.Context, path []int) ([]string, error), opts []Option) ([]int, error) {
+	opts = append(opts, withChildren(children))
+	idxs, err := f.find(items, func(i int) string { return items[i] }, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	f.stateMu.RLock()
+	defer f.stateMu.RUnlock()
+	return append(f.path(), idxs[0]), nil
+}
+
+// FindNested displays a UI that provides fuzzy finding against nested lists,
+// such as clusters, namespaces and pods. items is the top-level list.
+//
+// Accepting an item calls children with the path of the item, which holds the
+// index of the accepted item in each list, and the returned items are
+// displayed as the list under it with a new query. Backspace on an empty
+// query goes back to the previous list with its query and cursor. The path of
+// the current list is displayed in the header line. If children returns no
+// items, FindNested returns the path of the accepted item. If children returns
+// an error, it is displayed in the header area.
+//
+// children is called in the background, and a spinner is displayed in the
+// number line until it returns. Esc or Backspace cancels it, which cancels
+// ctx, and the other keys are ignored meanwhile.
+//
+// Options which receive an item index, such as WithPreviewWindow, receive the
+// index in the current list.
+func FindNested(items []string, children func(ctx context.Context, path []int) ([]string, error), opts ...Option) ([]int, error) {
+	f := newFinder()
+	return f.FindNested(items, children, opts...)
+}
+
+func (f *finder) FindNested(items []string, children func(ctx context.Context, path []int) ([]string, error), opts ...Option) ([]int, error) {
+	return f.findNested(items, children, opts)
+}
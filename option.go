@@ -7,7 +7,7 @@ import (
 
 type opt struct {
 	mode          mode
-	previewFunc   func(i, width, height int) string
+	previewFunc   func(ctx context.Context, i, width, height int) PreviewResult
 	multi         bool
 	hotReload     bool
 	hotReloadLock sync.Locker
@@ -18,6 +18,53 @@ type opt struct {
 	query         string
 	selectOne     bool
 	preselected   func(i int) bool
+	itemSource    ItemSource
+	itemKey       func(i int) string
+	follow        bool
+	reloadKeyName string
+	reloadKey     keyBinding
+	reloader      func(ctx context.Context) ([]string, error)
+
+	querySource       func(ctx context.Context, query string) ([]string, error)
+	querySourceFilter bool
+
+	commandName string
+	commandArgs []string
+
+	multiLine  bool
+	ansi       bool
+	itemStyle  func(i int) Style
+	annotation func(i int) string
+
+	tableColumns []Column
+	tableRow     func(i int) []string
+
+	group            func(i int) string
+	rankAcrossGroups bool
+
+	treeParent func(i int) int
+	treeResult TreeResult
+
+	children func(ctx context.Context, path []int) ([]string, error)
+
+	layout Layout
+
+	heightSpec string
+	height     height
+
+	previewPosition PreviewPosition
+	previewSizeSpec string
+	previewSize     previewSize
+
+	asyncPreview     bool
+	previewCacheSize int
+
+	previewToggleKeyName string
+	previewToggleKey     keyBinding
+	previewHidden        bool
+	previewMinWidth      int
+
+	previewMouse bool
 }
 
 type mode int
@@ -37,6 +84,9 @@ var defaultOption = opt{
 	promptString:  "> ",
 	hotReloadLock: &sync.Mutex{}, // this won't resolve the race condition but avoid nil panic
 	preselected:   func(i int) bool { return false },
+
+	previewSizeSpec:  "50%",
+	previewCacheSize: 64,
 }
 
 // Option represents available fuzzy-finding options.
@@ -58,8 +108,68 @@ func WithMode(m mode) Option {
 //
 // If f is nil, the preview feature is disabled.
 func WithPreviewWindow(f func(i, width, height int) string) Option {
+	return func(o *opt) {
+		o.asyncPreview = false
+		if f == nil {
+			o.previewFunc = nil
+			return
+		}
+		o.previewFunc = func(_ context.Context, i, width, height int) PreviewResult {
+			return PreviewResult{Text: f(i, width, height)}
+		}
+	}
+}
+
+// WithPreviewWindowResult is the same as WithPreviewWindow, but f returns a
+// PreviewResult which also specifies the initial scroll position of the preview
+// window, such as a matched line of a file.
+//
+// The preview window is scrolled by Shift with Up, Down, PgUp, PgDn, Home or
+// End, or by the mouse wheel over it if WithPreviewMouse is passed. The scroll
+// position is reset when the cursor moves to another item.
+func WithPreviewWindowResult(f func(i, width, height int) PreviewResult) Option {
+	return func(o *opt) {
+		o.asyncPreview = false
+		if f == nil {
+			o.previewFunc = nil
+			return
+		}
+		o.previewFunc = func(_ context.Context, i, width, height int) PreviewResult {
+			return f(i, width, height)
+		}
+	}
+}
+
+// WithPreviewMouse enables scrolling the preview window by the mouse wheel over
+// it. The finder captures the mouse to receive the wheel events, so the text in
+// the terminal can't be selected by the mouse as usual while the finder runs.
+// Most terminals still select the text by dragging with Shift.
+func WithPreviewMouse() Option {
+	return func(o *opt) {
+		o.previewMouse = true
+	}
+}
+
+// WithAsyncPreviewWindow is the same as WithPreviewWindowResult, but f is
+// called in the background so that a slow preview, such as running a command,
+// doesn't block the user's input. "loading..." is displayed until f returns.
+// ctx is cancelled when the cursor moves to another item before f returns, or
+// the finder is closed.
+//
+// The recent previews are cached. The size of the cache is specified by
+// WithPreviewCacheSize.
+func WithAsyncPreviewWindow(f func(ctx context.Context, i, width, height int) PreviewResult) Option {
 	return func(o *opt) {
 		o.previewFunc = f
+		o.asyncPreview = f != nil
+	}
+}
+
+// WithPreviewCacheSize specifies the number of previews cached by
+// WithAsyncPreviewWindow. The default is 64.
+func WithPreviewCacheSize(n int) Option {
+	return func(o *opt) {
+		o.previewCacheSize = n
 	}
 }
 
@@ -74,6 +184,9 @@ func WithHotReload() Option {
 }
 
 // WithHotReloadLock reloads the passed slice automatically when some entries are appended.
+// It is an ItemSource which polls the length of the slice. itemFunc is called only for the
+// appended entries, so entries must not be changed in place. If the length decreases, or
+// the keys passed by WithItemKey are changed, all entries are reloaded.
 // The caller must pass a pointer of the slice instead of the slice itself.
 // The caller must pass a RLock which is used to synchronize access to the slice.
 // The caller MUST NOT lock in the itemFunc passed to Find / FindMulti because it will be locked by the fuzzyfinder.
@@ -85,6 +198,283 @@ func WithHotReloadLock(lock sync.Locker) Option {
 	}
 }
 
+// WithItemSource uses src as the items instead of the slice passed to Find or
+// FindMulti. Changes to the items are received from src.Changes, and the
+// returned indices are the ones of src at the time Find returns.
+func WithItemSource(src ItemSource) Option {
+	return func(o *opt) {
+		o.itemSource = src
+	}
+}
+
+// WithItemKey specifies a function which returns the key identifying the i-th
+// item, where i is the item index. When the items are changed by hot
+// reloading or an ItemSource, the selections, the preselections and the
+// cursor follow the items which have the same keys instead of the same
+// indices. The returned indices are the current indices of the selected items.
+//
+// If used together with WithHotReloadLock, f is called while the lock is held.
+func WithItemKey(f func(i int) string) Option {
+	return func(o *opt) {
+		o.itemKey = f
+	}
+}
+
+// WithFollow makes the cursor follow the newest item, which has the largest
+// index, like "tail -f". It is useful together with WithHotReloadLock or
+// WithItemSource for log and event viewers.
+// Following stops once the user moves the cursor, and CTRL-T resumes it.
+// Whether the cursor is following is displayed in the number line.
+func WithFollow() Option {
+	return func(o *opt) {
+		o.follow = true
+	}
+}
+
+// WithReload binds key to reloading the items by loader. key is a key name
+// such as "ctrl-r", "f5" or "alt-r". If key is invalid or is already bound by
+// the finder, such as "ctrl-p" or "enter", Find returns an error.
+//
+// loader is called in the background, and a spinner is displayed in the number
+// line while it runs. Pressing key again cancels the running loader. The
+// returned items replace the current ones, and the returned indices of Find and
+// FindMulti are the ones of the latest items. The query is kept, and the cursor
+// and the selections follow the items which have the same keys passed by
+// WithItemKey, or the same strings if WithItemKey is not passed. If loader
+// returns an error, it is displayed in the header area.
+//
+// If used together with WithCommandSource, loader may be nil to run the
+// command again by key.
+func WithReload(key string, loader func(ctx context.Context) ([]string, error)) Option {
+	return func(o *opt) {
+		o.reloadKeyName = key
+		o.reloader = loader
+	}
+}
+
+// WithQuerySource makes f provide the items for each query instead of the
+// slice passed to Find or FindMulti. It is useful when the items can't be
+// loaded at once, such as the results of a code search.
+//
+// f is called with the initial query when the finder starts, and then each
+// time the query is changed and left unchanged for a short time. The context
+// passed to the running call is cancelled when the query is changed. The
+// returned items are displayed as they are, in the returned order, unless
+// WithQuerySourceFilter is passed. If f returns an error, it is displayed in
+// the header area.
+//
+// The returned indices of Find and FindMulti are the ones of the displayed
+// items, which the caller can't tell from the results of the other calls. Use
+// FindQuery or FindMultiQuery to get the selected items instead.
+func WithQuerySource(f func(ctx context.Context, query string) ([]string, error)) Option {
+	return func(o *opt) {
+		o.querySource = f
+	}
+}
+
+// WithQuerySourceFilter filters the items returned by the function passed by
+// WithQuerySource with the query again, like the items passed to Find.
+func WithQuerySourceFilter() Option {
+	return func(o *opt) {
+		o.querySourceFilter = true
+	}
+}
+
+// WithCommandSource runs the command named name with args and uses the lines
+// of its stdout as the items instead of the slice passed to Find or FindMulti.
+// The lines are added as soon as they are read, and a spinner is displayed in
+// the number line until the command exits. The last line of its stderr and a
+// non-zero exit status are displayed in the header area. The command is killed
+// when the finder returns.
+//
+// The returned indices of Find and FindMulti are the line numbers, starting at
+// 0, of the stdout of the last run. Use FindCommand or FindMultiCommand to get
+// the selected lines instead.
+func WithCommandSource(name string, args ...string) Option {
+	return func(o *opt) {
+		o.commandName = name
+		o.commandArgs = args
+	}
+}
+
+// WithMultiLineItems displays items containing newlines in multiple rows. An
+// item takes as many rows as it has lines, up to 5 rows, and "…" is displayed
+// at the end of the last row if some lines are omitted. The cursor, scrolling
+// and PgUp / PgDn move by items.
+func WithMultiLineItems() Option {
+	return func(o *opt) {
+		o.multiLine = true
+	}
+}
+
+// WithANSI interprets SGR sequences in item strings, such as the output of
+// "git log --color". Items are matched without the sequences and drawn with
+// the colors and the attributes they specify. The matched characters are
+// highlighted on top of them. The strings returned by FindStream and its
+// variants don't contain the sequences.
+//
+// Note that the items are held both with and without the sequences, so each
+// item takes twice as much memory.
+func WithANSI() Option {
+	return func(o *opt) {
+		o.ansi = true
+	}
+}
+
+// WithItemStyle specifies a function which returns the style of the i-th item,
+// such as red for failing tests. i is the item index. f is called only for the
+// displayed items.
+//
+// The colors specified by WithANSI are drawn on top of the style, and the
+// matched characters and the cursor line are highlighted on top of them. The
+// foreground color is kept in the cursor line unless it is the default one.
+func WithItemStyle(f func(i int) Style) Option {
+	return func(o *opt) {
+		o.itemStyle = f
+	}
+}
+
+// WithAnnotation specifies a function which returns the annotation of the i-th
+// item, such as a size or a date. i is the item index. The annotation is
+// displayed dimmed and right-aligned in the item line, and it is never matched
+// against the query. The item text is shortened to keep the annotation
+// visible, and the annotation is shortened only if the window is too narrow.
+func WithAnnotation(f func(i int) string) Option {
+	return func(o *opt) {
+		o.annotation = f
+	}
+}
+
+// WithTable displays the items as a table of columns. row returns the cells
+// of the i-th item, where i is the item index. itemFunc is not used and may be
+// nil. The headers of columns are displayed above the items.
+//
+// The width of each column fits the widest cell in the displayed rows, up to
+// Column.MaxWidth, and wide columns are shortened with ".." to fit the window.
+// The query is matched against the cells of the columns without
+// Column.NoMatch.
+func WithTable(columns []Column, row func(i int) []string) Option {
+	return func(o *opt) {
+		o.tableColumns = columns
+		o.tableRow = row
+	}
+}
+
+// WithGroups groups the items under section headers such as "Recent" and
+// "Branches". f returns the name of the group of the i-th item, where i is the
+// item index. The header of each group is displayed above its items and can't
+// be selected. Groups which have no matched items are hidden.
+//
+// Items in the same group should be adjacent because the items are displayed
+// in the original order while the query is empty. While filtering, the items
+// are ranked in each group and the groups keep the order of their first items
+// unless WithRankAcrossGroups is passed.
+func WithGroups(f func(i int) string) Option {
+	return func(o *opt) {
+		o.group = f
+	}
+}
+
+// WithRankAcrossGroups ranks the items passed by WithGroups across all groups,
+// so the group which has the best matched item is displayed first. The items
+// in each group are still displayed together.
+func WithRankAcrossGroups() Option {
+	return func(o *opt) {
+		o.rankAcrossGroups = true
+	}
+}
+
+// WithTree displays the items as a tree such as a file tree. parent returns
+// the index of the parent of the i-th item, or -1 if it is a root. i is the
+// item index. The children of each item are displayed under it in the order
+// of their indices.
+//
+// Only the roots are displayed at first. Shift-Right expands the item pointed
+// by the cursor, and Shift-Left collapses it or moves the cursor to its
+// parent. While filtering, the matched items are displayed with their
+// ancestors, and the cursor is moved to the best matched item.
+func WithTree(parent func(i int) int) Option {
+	return func(o *opt) {
+		o.treeParent = parent
+	}
+}
+
+// WithTreeResult specifies which items of the tree passed by WithTree can be
+// returned. The default is TreeResultNodes.
+func WithTreeResult(r TreeResult) Option {
+	return func(o *opt) {
+		o.treeResult = r
+	}
+}
+
+// WithLayout specifies the arrangement of the prompt line and the items. The
+// default layout is LayoutDefault. The arrow keys, PgUp and PgDn move the
+// cursor in the same direction on the screen in every layout.
+//
+// In LayoutReverse and LayoutReverseList, the cursor begins at the first item,
+// which is displayed at the top, and WithCursorPosition is ignored.
+func WithLayout(l Layout) Option {
+	return func(o *opt) {
+		o.layout = l
+	}
+}
+
+// WithHeight displays the finder in the rows below the cursor instead of the
+// whole screen, like fzf --height. h is the number of rows such as "10", or a
+// percentage of the terminal height such as "40%". If there isn't enough room
+// below the cursor, the earlier output of the terminal is scrolled up and kept.
+// The rows are cleared and the cursor is moved back to the first of them when
+// the finder returns. If h is invalid, Find returns an error.
+//
+// The inline mode is supported only on Unix, and the whole screen is used on
+// the other platforms.
+func WithHeight(h string) Option {
+	return func(o *opt) {
+		o.heightSpec = h
+	}
+}
+
+// WithPreviewLayout places the preview window at position. size is the width,
+// or the height for PreviewTop and PreviewBottom, of the preview window. It is
+// the number of cells such as "40", or a percentage of the terminal such as
+// "50%". The default is PreviewRight and "50%".
+//
+// If the terminal is too narrow to display the list and the preview window
+// side by side, PreviewRight and PreviewLeft are switched to PreviewBottom and
+// PreviewTop respectively.
+func WithPreviewLayout(position PreviewPosition, size string) Option {
+	return func(o *opt) {
+		o.previewPosition = position
+		o.previewSizeSpec = size
+	}
+}
+
+// WithPreviewToggle binds key to showing and hiding the preview window. key is
+// a key name such as "f2" or "alt-p". If key is invalid or is already bound by
+// the finder or WithReload, Find returns an error.
+func WithPreviewToggle(key string) Option {
+	return func(o *opt) {
+		o.previewToggleKeyName = key
+	}
+}
+
+// WithPreviewHidden hides the preview window until the key passed by
+// WithPreviewToggle is pressed. The function passed by WithPreviewWindow is
+// not called while the preview window is hidden.
+func WithPreviewHidden() Option {
+	return func(o *opt) {
+		o.previewHidden = true
+	}
+}
+
+// WithPreviewMinWidth hides the preview window while the terminal is narrower
+// than n columns.
+func WithPreviewMinWidth(n int) Option {
+	return func(o *opt) {
+		o.previewMinWidth = n
+	}
+}
+
 type cursorPosition int
 
 const (
@@ -120,6 +510,13 @@ func withMulti() Option {
 	}
 }
 
+// withChildren enables to drill down into the children of items. See FindNested.
+func withChildren(f func(ctx context.Context, path []int) ([]string, error)) Option {
+	return func(o *opt) {
+		o.children = f
+	}
+}
+
 // WithHeader enables to set the header.
 func WithHeader(s string) Option {
 	return func(o *opt) {
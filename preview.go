@@ -0,0 +1,362 @@
+package fuzzyfinder
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
+)
+
+// PreviewPosition represents where the preview window is placed.
+type PreviewPosition int
+
+const (
+	// PreviewRight places the preview window on the right side of the list.
+	// It is the default position.
+	PreviewRight PreviewPosition = iota
+	// PreviewLeft places the preview window on the left side of the list.
+	PreviewLeft
+	// PreviewTop places the preview window above the list.
+	PreviewTop
+	// PreviewBottom places the preview window below the list.
+	PreviewBottom
+)
+
+// The minimum sizes of the list and the preview window. If the terminal is
+// too narrow for both of them, the preview window is placed above or below
+// the list. If it is also too low, the preview window is hidden.
+const (
+	minListWidth     = 20
+	minPreviewWidth  = 20
+	minListHeight    = 3 // The prompt line, the number line and an item line.
+	minPreviewHeight = 3 // The borders and a line.
+)
+
+// previewSize represents the size passed by WithPreviewLayout.
+type previewSize struct {
+	n       int
+	percent bool
+}
+
+// parsePreviewSize parses a size such as "40" or "50%".
+func parsePreviewSize(s string) (previewSize, error) {
+	num, percent := strings.CutSuffix(s, "%")
+	n, err := strconv.Atoi(num)
+	if err != nil || n <= 0 || (percent && n > 100) {
+		return previewSize{}, errors.Errorf("invalid preview size: %s", s)
+	}
+	return previewSize{n: n, percent: percent}, nil
+}
+
+// cells returns the number of cells of the preview window in total cells.
+// A percentage is rounded up so that the preview window takes the larger
+// half of an odd number of cells.
+func (s previewSize) cells(total int) int {
+	if s.percent {
+		return total - total*(100-s.n)/100
+	}
+	return s.n
+}
+
+// rect represents a rectangle area of the terminal.
+type rect struct {
+	x, y          int
+	width, height int
+}
+
+// pane is a screen which draws in an area of another screen. The positions
+// passed to pane are relative to the area, and cells out of the area are
+// discarded.
+type pane struct {
+	screen
+	rect
+}
+
+func (p pane) SetContent(x, y int, r rune, comb []rune, style tcell.Style) {
+	if x < 0 || y < 0 || x >= p.width || y >= p.height {
+		return
+	}
+	p.screen.SetContent(p.x+x, p.y+y, r, comb, style)
+}
+
+func (p pane) ShowCursor(x, y int) {
+	p.screen.ShowCursor(p.x+x, p.y+y)
+}
+
+func (p pane) Size() (int, int) {
+	return p.width, p.height
+}
+
+// panes returns the areas of the list and the preview window. The area of
+// the preview window is empty if it is not displayed.
+func (f *finder) panes() (list, preview rect) {
+	width, height := f.term.Size()
+	list = rect{width: width, height: height}
+	if f.opt.previewFunc == nil || f.state.previewHidden || width < f.opt.previewMinWidth {
+		return list, rect{}
+	}
+
+	pos := f.opt.previewPosition
+	if pos == PreviewRight || pos == PreviewLeft {
+		// The list and the preview window are separated by a column.
+		n := min(max(f.opt.previewSize.cells(width), minPreviewWidth), width-1-minListWidth)
+		if n >= minPreviewWidth {
+			if pos == PreviewRight {
+				return rect{width: width - n - 1, height: height}, rect{x: width - n, width: n, height: height}
+			}
+			return rect{x: n + 1, width: width - n - 1, height: height}, rect{width: n, height: height}
+		}
+		// The terminal is too narrow.
+		if pos == PreviewRight {
+			pos = PreviewBottom
+		} else {
+			pos = PreviewTop
+		}
+	}
+
+	n := min(max(f.opt.previewSize.cells(height), minPreviewHeight), height-minListHeight)
+	if n < minPreviewHeight {
+		return list, rect{}
+	}
+	if pos == PreviewTop {
+		return rect{y: n, width: width, height: height - n}, rect{width: width, height: n}
+	}
+	return rect{width: width, height: height - n}, rect{y: height - n, width: width, height: n}
+}
+
+// listPane returns the screen in which the prompt line and the item lines are
+// drawn.
+func (f *finder) listPane() pane {
+	list, _ := f.panes()
+	return pane{screen: f.term, rect: list}
+}
+
+// previewPane returns the screen in which the preview window is drawn.
+func (f *finder) previewPane() pane {
+	_, preview := f.panes()
+	return pane{screen: f.term, rect: preview}
+}
+
+// PreviewResult is the result of the function passed to
+// WithPreviewWindowResult.
+type PreviewResult struct {
+	// Text is the preview of the item. It may contain SGR sequences.
+	Text string
+	// Offset is the line displayed at the top of the preview window when the
+	// item is previewed. The first line is 0.
+	Offset int
+	// Center displays the line at Offset in the middle of the preview window
+	// instead of at the top.
+	Center bool
+}
+
+// previewScroll is the scroll position of the preview window set by the user.
+type previewScroll struct {
+	// idx is the previewed item.
+	idx int
+	// offset is the first displayed line.
+	offset int
+}
+
+// previewIdx returns the item under the cursor, or -1 if there is no matched
+// item.
+func (f *finder) previewIdx() int {
+	if f.state.matchedLen() == 0 {
+		return -1
+	}
+	return f.state.matchedAt(f.state.y).Idx
+}
+
+// preview returns the preview of the item idx. If WithAsyncPreviewWindow is
+// passed, it returns a placeholder until the preview is computed.
+func (f *finder) preview(idx int) PreviewResult {
+	width, height := f.term.Size()
+	if f.previewer == nil {
+		return f.opt.previewFunc(context.Background(), idx, width, height)
+	}
+	key := previewKey{idx: idx, width: width, height: height}
+	if idx >= 0 {
+		key.item = f.state.items.at(idx)
+	}
+	res, ok := f.asyncPreview(key)
+	if !ok {
+		return PreviewResult{Text: loadingPreview}
+	}
+	return res
+}
+
+// loadingPreview is displayed while the preview is computed in the
+// background.
+const loadingPreview = "loading..."
+
+// previewKey identifies a preview. The preview depends on the terminal size as
+// well as the previewed item. The item is identified by its index and string,
+// so appending items doesn't invalidate the cached previews, while updating,
+// removing and replacing items does.
+type previewKey struct {
+	idx, width, height int
+	item               string
+}
+
+// previewer computes previews in the background and caches them if
+// WithAsyncPreviewWindow is passed.
+type previewer struct {
+	// ctx is cancelled when the finder is closed.
+	ctx context.Context
+
+	mu    sync.Mutex
+	cache *lru[previewKey, PreviewResult]
+	// pending is the preview being computed, and cancel cancels it. cancel is
+	// nil if no preview is being computed.
+	pending previewKey
+	cancel  context.CancelFunc
+}
+
+func newPreviewer(ctx context.Context, cacheSize int) *previewer {
+	// The cache holds at least the current preview.
+	return &previewer{ctx: ctx, cache: newLRU[previewKey, PreviewResult](max(cacheSize, 1))}
+}
+
+// asyncPreview returns the cached preview of key. If it is not cached, it
+// starts computing the preview in the background and returns false. The
+// computation for another preview is cancelled because the cursor has moved.
+func (f *finder) asyncPreview(key previewKey) (PreviewResult, bool) {
+	p := f.previewer
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil && p.pending != key {
+		p.cancel()
+		p.cancel = nil
+	}
+	if res, ok := p.cache.get(key); ok {
+		return res, true
+	}
+	if p.cancel != nil {
+		// The preview is being computed.
+		return PreviewResult{}, false
+	}
+
+	// The item shares the memory of the arena, which may be changed later.
+	key.item = strings.Clone(key.item)
+	ctx, cancel := context.WithCancel(p.ctx)
+	p.pending, p.cancel = key, cancel
+	go func() {
+		res := f.opt.previewFunc(ctx, key.idx, key.width, key.height)
+
+		p.mu.Lock()
+		if ctx.Err() != nil {
+			// The cursor has moved, or the finder is closed.
+			p.mu.Unlock()
+			return
+		}
+		cancel()
+		p.cancel = nil
+		p.cache.add(key, res)
+		p.mu.Unlock()
+
+		f.draw(0)
+	}()
+	return PreviewResult{}, false
+}
+
+// previewOffset returns the first line of res displayed in rows lines of the
+// preview window. The scroll position set by the user is used until the
+// cursor moves to another item.
+func (f *finder) previewOffset(idx int, res PreviewResult, rows int) int {
+	offset := res.Offset
+	if res.Center {
+		offset -= (rows - 1) / 2
+	}
+	if s := f.state.previewScroll; s != nil && s.idx == idx {
+		offset = s.offset
+	}
+	lines := strings.Count(res.Text, "\n") + 1
+	return max(min(offset, lines-rows), 0)
+}
+
+// scrollPreview scrolls the preview window of the item under the cursor by k,
+// which moves it by a line, a page, or to the top or the bottom in the same
+// way as the item lines. It reports whether k is a key for scrolling.
+func (f *finder) scrollPreview(k tcell.Key) bool {
+	switch k {
+	case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyHome, tcell.KeyEnd:
+	default:
+		return false
+	}
+	p := f.previewPane()
+	if p.width == 0 {
+		return false
+	}
+
+	idx := f.previewIdx()
+	res := f.preview(idx)
+	// The lines between the top and the bottom borders.
+	rows := p.height - 2
+	offset := f.previewOffset(idx, res, rows)
+	switch k {
+	case tcell.KeyUp:
+		offset--
+	case tcell.KeyDown:
+		offset++
+	case tcell.KeyPgUp:
+		offset -= rows
+	case tcell.KeyPgDn:
+		offset += rows
+	case tcell.KeyHome:
+		offset = 0
+	case tcell.KeyEnd:
+		offset = strings.Count(res.Text, "\n")
+	}
+	f.state.previewScroll = &previewScroll{idx: idx, offset: offset}
+	// Keep the offset within the text.
+	f.state.previewScroll.offset = f.previewOffset(idx, res, rows)
+	return true
+}
+
+// previewKey scrolls the preview window by Shift with Up, Down, PgUp, PgDn,
+// Home or End. It reports whether e is handled.
+func (f *finder) previewKey(e *tcell.EventKey) bool {
+	if f.opt.previewFunc == nil || e.Modifiers()&tcell.ModShift == 0 {
+		return false
+	}
+	return f.scrollPreview(e.Key())
+}
+
+// previewMouse scrolls the preview window by the mouse wheel over it if
+// WithPreviewMouse is passed.
+func (f *finder) previewMouse(e *tcell.EventMouse) {
+	if f.opt.previewFunc == nil || !f.opt.previewMouse {
+		return
+	}
+	x, y := e.Position()
+	p := f.previewPane()
+	if x < p.x || x >= p.x+p.width || y < p.y || y >= p.y+p.height {
+		return
+	}
+	switch {
+	case e.Buttons()&tcell.WheelUp != 0:
+		f.scrollPreview(tcell.KeyUp)
+	case e.Buttons()&tcell.WheelDown != 0:
+		f.scrollPreview(tcell.KeyDown)
+	}
+}
+
+// togglePreview shows or hides the preview window.
+func (f *finder) togglePreview() {
+	f.state.previewHidden = !f.state.previewHidden
+	// The item lines may be lower than before.
+	f.state.cursorY = min(f.state.cursorY, max(f.itemAreaRows()-1, 0))
+	f.fitCursor()
+}
+
+// resetPreviewScroll discards the scroll position set by the user if the
+// cursor has moved to another item.
+func (f *finder) resetPreviewScroll() {
+	if s := f.state.previewScroll; s != nil && s.idx != f.previewIdx() {
+		f.state.previewScroll = nil
+	}
+}
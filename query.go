@@ -0,0 +1,88 @@
+package fuzzyfinder
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// queryDebounce is the delay before the function passed by WithQuerySource is
+// called after the query is changed.
+const queryDebounce = 100 * time.Millisecond
+
+// queryChanged calls the function passed by WithQuerySource with the current
+// query after queryDebounce. The running call is cancelled immediately because
+// its result is no longer needed. It must be called while stateMu is locked.
+func (f *finder) queryChanged(ctx context.Context) {
+	f.cancelLoader()
+	if f.queryTimer != nil {
+		f.queryTimer.Stop()
+	}
+	gen := f.state.reloadGen
+	f.queryTimer = time.AfterFunc(queryDebounce, func() {
+		f.stateMu.Lock()
+		defer f.stateMu.Unlock()
+		if gen != f.state.reloadGen || ctx.Err() != nil {
+			// The query is changed again, or the finder is closed.
+			return
+		}
+		f.loadQuery(ctx)
+	})
+}
+
+// loadQuery calls the function passed by WithQuerySource with the current query
+// in the background. It must be called while stateMu is locked.
+func (f *finder) loadQuery(ctx context.Context) {
+	query := string(f.state.input)
+	f.reload(ctx, func(ctx context.Context) ([]string, error) {
+		return f.opt.querySource(ctx, query)
+	})
+}
+
+// filtersLocally reports whether the items are filtered by the query. If
+// WithQuerySource is passed, the items are displayed as they are unless
+// WithQuerySourceFilter is also passed.
+func (f *finder) filtersLocally() bool {
+	return f.opt.querySource == nil || f.opt.querySourceFilter
+}
+
+// FindQuery displays a UI in which source provides the items for each query,
+// like WithQuerySource. FindQuery returns the selected string, which is one
+// of the items returned by the call to source for the displayed items.
+//
+// FindQuery returns ErrAbort if a call to FindQuery is finished with no
+// selection.
+func FindQuery(source func(ctx context.Context, query string) ([]string, error), opts ...Option) (string, error) {
+	f := newFinder()
+	return f.FindQuery(source, opts...)
+}
+
+func (f *finder) FindQuery(source func(ctx context.Context, query string) ([]string, error), opts ...Option) (string, error) {
+	res, err := f.findQuery(source, opts)
+	if err != nil {
+		return "", err
+	}
+	return res[0], nil
+}
+
+// FindMultiQuery is nearly the same as FindQuery. The only difference from
+// FindQuery is that the user can select multiple items at once, by using the
+// tab key.
+func FindMultiQuery(source func(ctx context.Context, query string) ([]string, error), opts ...Option) ([]string, error) {
+	f := newFinder()
+	return f.FindMultiQuery(source, opts...)
+}
+
+func (f *finder) FindMultiQuery(source func(ctx context.Context, query string) ([]string, error), opts ...Option) ([]string, error) {
+	opts = append(opts, withMulti())
+	return f.findQuery(source, opts)
+}
+
+func (f *finder) findQuery(source func(ctx context.Context, query string) ([]string, error), opts []Option) ([]string, error) {
+	if source == nil {
+		return nil, errors.New("source must not be nil")
+	}
+	_, res, err := f.findStrings(append(opts, WithQuerySource(source)))
+	return res, err
+}
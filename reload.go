@@ -0,0 +1,69 @@
+package fuzzyfinder
+
+import (
+	"context"
+	"time"
+)
+
+// reload runs loader in the background and replaces the items with the
+// returned ones. The running loader is cancelled. It must be called while
+// stateMu is locked.
+func (f *finder) reload(ctx context.Context, loader func(ctx context.Context) ([]string, error)) {
+	f.cancelLoader()
+	ctx, cancel := context.WithCancel(ctx)
+	f.cancelReload = cancel
+	gen := f.state.reloadGen
+	f.state.reloading = true
+
+	go func() {
+		defer cancel()
+
+		done := make(chan struct{})
+		defer close(done)
+		go f.animateSpinner(done)
+
+		items, err := loader(ctx)
+
+		f.stateMu.Lock()
+		if gen != f.state.reloadGen || ctx.Err() != nil {
+			// Another reload is started, or the finder is closed.
+			f.stateMu.Unlock()
+			return
+		}
+		f.state.reloading = false
+		if err != nil {
+			f.state.errMsg = err.Error()
+			f.stateMu.Unlock()
+			f.draw(0)
+			return
+		}
+		f.state.errMsg = ""
+		f.stateMu.Unlock()
+
+		f.applyChanges([]ItemChange{{Kind: itemsReset, Items: items}})
+	}()
+}
+
+// cancelLoader cancels the running loader and discards its result. It must be
+// called while stateMu is locked.
+func (f *finder) cancelLoader() {
+	if f.cancelReload != nil {
+		f.cancelReload()
+		f.cancelReload = nil
+	}
+	f.state.reloadGen++
+	f.state.reloading = false
+	f.state.drilling = false
+}
+
+// animateSpinner redraws the screen periodically until done is closed.
+func (f *finder) animateSpinner(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(100 * time.Millisecond):
+			f.draw(0)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package fuzzyfinder
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// findSeq reads seq in the background and passes its items to findStream.
+// seq is stopped when the finder returns or when it yields an error.
+func (f *finder) findSeq(seq iter.Seq2[string, error], opts []Option) ([]int, []string, error) {
+	return f.findProduced(func(ctx context.Context, ch chan<- string) error {
+		for item, err := range seq {
+			if err != nil {
+				return errors.Wrap(err, "iterator failed")
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case ch <- item:
+			}
+		}
+		return nil
+	}, opts)
+}
+
+// withoutError adapts seq, which never fails, to findSeq.
+func withoutError(seq iter.Seq[string]) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for item := range seq {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+func findSeq2[T any](f *finder, seq iter.Seq2[int, T], label func(int, T) string, opts []Option) ([]T, error) {
+	if label == nil {
+		return nil, errors.New("label must not be nil")
+	}
+	if err := checkTypedOptions(opts); err != nil {
+		return nil, err
+	}
+
+	var (
+		mu     sync.Mutex
+		values []T
+	)
+	idxs, _, err := f.findSeq(func(yield func(string, error) bool) {
+		for k, v := range seq {
+			mu.Lock()
+			values = append(values, v)
+			mu.Unlock()
+			if !yield(label(k, v), nil) {
+				return
+			}
+		}
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	res := make([]T, len(idxs))
+	for i, idx := range idxs {
+		res[i] = values[idx]
+	}
+	return res, nil
+}
+
+// FindSeq displays a UI that provides fuzzy finding against the strings
+// produced by seq. seq is read in the background and each item is added as
+// soon as it is produced, like FindStream. seq is stopped early when the user
+// accepts or aborts. Use FindSeqErr for a sequence which may fail.
+//
+// If seq panics, the finder is closed and the panic is raised again in the
+// goroutine which called FindSeq.
+func FindSeq(seq iter.Seq[string], opts ...Option) (string, error) {
+	f := newFinder()
+	return f.FindSeq(seq, opts...)
+}
+
+func (f *finder) FindSeq(seq iter.Seq[string], opts ...Option) (string, error) {
+	_, res, err := f.findSeq(withoutError(seq), opts)
+	if err != nil {
+		return "", err
+	}
+	return res[0], nil
+}
+
+// FindMultiSeq is nearly the same as FindSeq. The only difference from FindSeq
+// is that the user can select multiple items at once, by using the tab key.
+func FindMultiSeq(seq iter.Seq[string], opts ...Option) ([]string, error) {
+	f := newFinder()
+	return f.FindMultiSeq(seq, opts...)
+}
+
+func (f *finder) FindMultiSeq(seq iter.Seq[string], opts ...Option) ([]string, error) {
+	opts = append(opts, withMulti())
+	_, res, err := f.findSeq(withoutError(seq), opts)
+	return res, err
+}
+
+// FindSeqErr is nearly the same as FindSeq, but seq is a sequence of pairs of
+// an item and an error such as the rows of a database cursor. Reading seq
+// stops at the first non-nil error. If the user selects nothing after that,
+// FindSeqErr returns an error wrapping it instead of ErrAbort.
+func FindSeqErr(seq iter.Seq2[string, error], opts ...Option) (string, error) {
+	f := newFinder()
+	return f.FindSeqErr(seq, opts...)
+}
+
+func (f *finder) FindSeqErr(seq iter.Seq2[string, error], opts ...Option) (string, error) {
+	_, res, err := f.findSeq(seq, opts)
+	if err != nil {
+		return "", err
+	}
+	return res[0], nil
+}
+
+// FindMultiSeqErr is nearly the same as FindSeqErr. The only difference from
+// FindSeqErr is that the user can select multiple items at once, by using the
+// tab key.
+func FindMultiSeqErr(seq iter.Seq2[string, error], opts ...Option) ([]string, error) {
+	f := newFinder()
+	return f.FindMultiSeqErr(seq, opts...)
+}
+
+func (f *finder) FindMultiSeqErr(seq iter.Seq2[string, error], opts ...Option) ([]string, error) {
+	opts = append(opts, withMulti())
+	_, res, err := f.findSeq(seq, opts)
+	return res, err
+}
+
+// FindSeq2 is nearly the same as FindSeq, but it reads pairs of a key and a
+// value such as the ones produced by slices.All. label is called with each
+// pair to get its display string. FindSeq2 returns the selected value.
+//
+// Like FindT, FindSeq2 returns an error if WithItemSource, WithReload,
+// WithQuerySource or WithCommandSource is passed.
+func FindSeq2[T any](seq iter.Seq2[int, T], label func(int, T) string, opts ...Option) (T, error) {
+	res, err := findSeq2(newFinder(), seq, label, opts)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return res[0], nil
+}
+
+// FindMultiSeq2 is nearly the same as FindSeq2. The only difference from
+// FindSeq2 is that the user can select multiple items at once, by using the
+// tab key.
+func FindMultiSeq2[T any](seq iter.Seq2[int, T], label func(int, T) string, opts ...Option) ([]T, error) {
+	return findSeq2(newFinder(), seq, label, append(opts, withMulti()))
+}
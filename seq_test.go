@@ -0,0 +1,114 @@
+package fuzzyfinder_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
+)
+
+func TestFindSeq(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	events := append(runes("glow"), key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+	term.SetEventsV2(events...)
+
+	stopped := make(chan struct{})
+	seq := func(yield func(string) bool) {
+		defer close(stopped)
+		for _, tr := range tracks {
+			if !yield(tr.Name) {
+				return
+			}
+		}
+		// An endless sequence which must be stopped by the finder.
+		for i := 0; ; i++ {
+			if !yield(fmt.Sprintf("item%d", i)) {
+				return
+			}
+		}
+	}
+
+	item, err := f.FindSeq(seq)
+	if err != nil {
+		t.Fatalf("FindSeq must not return an error, but got '%s'", err)
+	}
+	if item != "glow" {
+		t.Errorf("expected item: glow, but got %s", item)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("the sequence must be stopped after FindSeq returns")
+	}
+}
+
+func TestFindSeqErr(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetEventsV2(key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+
+	errCursor := errors.New("cursor closed")
+	_, err := f.FindSeqErr(func(yield func(string, error) bool) {
+		if !yield("foo", nil) {
+			return
+		}
+		yield("", errCursor)
+	})
+	if !errors.Is(err, errCursor) {
+		t.Fatalf("FindSeqErr must return the iterator error, but got '%s'", err)
+	}
+}
+
+func TestFindSeq_panic(t *testing.T) {
+	t.Parallel()
+
+	f, _ := fuzzyfinder.NewWithMockedTerminal()
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("FindSeq must panic with the value of the iterator, but got %v", r)
+		}
+	}()
+	_, _ = f.FindSeq(func(yield func(string) bool) {
+		yield("foo")
+		panic("boom")
+	})
+	t.Error("FindSeq must panic")
+}
+
+func TestFindSeq2(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	events := append(runes("glow"), key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+	term.SetEventsV2(events...)
+
+	tr, err := fuzzyfinder.FindSeq2WithFinder(f, slices.All(tracks), func(_ int, tr *track) string {
+		return tr.Name
+	})
+	if err != nil {
+		t.Fatalf("FindSeq2 must not return an error, but got '%s'", err)
+	}
+	if tr != tracks[5] {
+		t.Errorf("expected track: %v, but got %v", tracks[5], tr)
+	}
+
+	t.Run("WithReload", func(t *testing.T) {
+		loader := func(context.Context) ([]string, error) { return []string{"a", "b", "c"}, nil }
+		_, err := fuzzyfinder.FindSeq2WithFinder(fuzzyfinder.New(), slices.All([]string{"a"}), func(_ int, s string) string {
+			return s
+		}, fuzzyfinder.WithReload("ctrl-r", loader))
+		if err == nil {
+			t.Error("FindSeq2 must return an error, but got nil")
+		}
+	})
+}
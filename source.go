@@ -0,0 +1,213 @@
+package fuzzyfinder
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ItemSource provides items to the fuzzy finder and notifies it of changes to
+// them.
+//
+// The finder reads the initial items by Len and Item, and then receives
+// changes from the channel returned by Changes. A change must describe a
+// mutation made after the initial items are read. After the finder returns,
+// it no longer receives from the channel, so sources should not block on
+// sending forever (e.g. use the context passed by WithContext).
+type ItemSource interface {
+	// Len returns the number of items.
+	Len() int
+	// Item returns the display string of the i-th item.
+	Item(i int) string
+	// Changes returns a channel which receives changes to the items. If it
+	// returns nil, the items are never changed.
+	Changes() <-chan ItemChange
+}
+
+// ItemChangeKind represents a kind of ItemChange.
+type ItemChangeKind int
+
+const (
+	// ItemsAppended means Items are appended to the end of the items.
+	// Index must be the number of items before the change.
+	ItemsAppended ItemChangeKind = iota
+	// ItemsRemoved means Count items starting at Index are removed. Items
+	// after them are shifted.
+	ItemsRemoved
+	// ItemsUpdated means the items starting at Index are replaced with Items
+	// in place.
+	ItemsUpdated
+
+	// itemsReset means all items are replaced with Items. It is used by
+	// internal sources only.
+	itemsReset ItemChangeKind = -1
+)
+
+// ItemChange represents a change to the items of an ItemSource.
+type ItemChange struct {
+	Kind ItemChangeKind
+	// Index is the index of the first item the change applies to.
+	Index int
+	// Items holds the display strings of the appended or updated items.
+	Items []string
+	// Count is the number of removed items.
+	Count int
+
+	// keys holds the keys of the appended or updated items. It is set by
+	// internal sources which read keys together with the items.
+	keys []string
+}
+
+// watcher is implemented by internal sources which produce changes in the
+// background instead of sending them to the channel returned by Changes.
+// watch is called after the initial items are read. It passes each set of
+// changes which must be applied at once to apply, and it must return when ctx
+// is done.
+type watcher interface {
+	watch(ctx context.Context, apply func([]ItemChange))
+}
+
+// loader is implemented by internal sources which are still receiving items.
+type loader interface {
+	loading() bool
+}
+
+// keyer is implemented by internal sources which read the keys passed by
+// WithItemKey together with the items.
+type keyer interface {
+	key(i int) string
+}
+
+// sliceSource is a static source which calls itemFunc for each item.
+type sliceSource struct {
+	n        int
+	itemFunc func(i int) string
+}
+
+func (s *sliceSource) Len() int                   { return s.n }
+func (s *sliceSource) Item(i int) string          { return s.itemFunc(i) }
+func (s *sliceSource) Changes() <-chan ItemChange { return nil }
+
+// hotReloadSource adapts a pointer to a slice passed with WithHotReloadLock to
+// ItemSource. It polls the length of the slice and emits changes when the
+// length changes. Because entries are expected to be appended, itemFunc is
+// called only for the new entries.
+type hotReloadSource struct {
+	rv       reflect.Value
+	itemFunc func(i int) string
+	itemKey  func(i int) string
+	lock     sync.Locker
+
+	// items holds the initial items. It is released when watching starts
+	// because the finder holds the items.
+	items []string
+	// n and keys hold the number of the last read items and their keys.
+	n    int
+	keys []string
+}
+
+func newHotReloadSource(rv reflect.Value, itemFunc, itemKey func(i int) string, lock sync.Locker) *hotReloadSource {
+	return &hotReloadSource{
+		rv:       rv,
+		itemFunc: itemFunc,
+		itemKey:  itemKey,
+		lock:     lock,
+	}
+}
+
+// Len reads all items of the slice at once and returns the number of them.
+func (s *hotReloadSource) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.items, s.keys = s.makeItems(0, s.rv.Len())
+	s.n = len(s.items)
+	return s.n
+}
+
+func (s *hotReloadSource) Item(i int) string          { return s.items[i] }
+func (s *hotReloadSource) Changes() <-chan ItemChange { return nil }
+func (s *hotReloadSource) key(i int) string           { return s.keys[i] }
+
+// makeItems calls itemFunc and itemKey for the items in [from, to). It must
+// be called while the lock is held.
+func (s *hotReloadSource) makeItems(from, to int) ([]string, []string) {
+	items := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		items = append(items, s.itemFunc(i))
+	}
+	if s.itemKey == nil {
+		return items, nil
+	}
+	keys := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		keys = append(keys, s.itemKey(i))
+	}
+	return items, keys
+}
+
+// appendedOnly reports whether the first n entries of the slice are unchanged.
+// Without WithItemKey, it is assumed that entries are only appended. It must
+// be called while the lock is held.
+func (s *hotReloadSource) appendedOnly(n int) bool {
+	if s.itemKey == nil {
+		return true
+	}
+	for i := 0; i < n; i++ {
+		if s.itemKey(i) != s.keys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keysIn returns the keys in [from, to) or nil if WithItemKey is not passed.
+func keysIn(keys []string, from, to int) []string {
+	if keys == nil {
+		return nil
+	}
+	return keys[from:to]
+}
+
+func (s *hotReloadSource) watch(ctx context.Context, apply func([]ItemChange)) {
+	s.items = nil
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(30 * time.Millisecond):
+			s.lock.Lock()
+			prev, curr := s.n, s.rv.Len()
+			if prev == curr {
+				s.lock.Unlock()
+				continue
+			}
+
+			if prev < curr && s.appendedOnly(prev) {
+				items, keys := s.makeItems(prev, curr)
+				s.lock.Unlock()
+
+				s.n = curr
+				if s.keys != nil {
+					s.keys = append(s.keys, keys...)
+				}
+				apply([]ItemChange{{Kind: ItemsAppended, Index: prev, Items: items, keys: keys}})
+				continue
+			}
+
+			// Existing items are changed as well, so all items are read again.
+			items, keys := s.makeItems(0, curr)
+			s.lock.Unlock()
+
+			n := min(prev, curr)
+			changes := []ItemChange{{Kind: ItemsUpdated, Index: 0, Items: items[:n], keys: keysIn(keys, 0, n)}}
+			if prev < curr {
+				changes = append(changes, ItemChange{Kind: ItemsAppended, Index: prev, Items: items[prev:], keys: keysIn(keys, prev, curr)})
+			} else {
+				changes = append(changes, ItemChange{Kind: ItemsRemoved, Index: curr, Count: prev - curr})
+			}
+			s.n, s.keys = curr, keys
+			apply(changes)
+		}
+	}
+}
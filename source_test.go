@@ -0,0 +1,51 @@
+package fuzzyfinder_test
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
+)
+
+type testSource struct {
+	items   []string
+	changes chan fuzzyfinder.ItemChange
+}
+
+func (s *testSource) Len() int                               { return len(s.items) }
+func (s *testSource) Item(i int) string                      { return s.items[i] }
+func (s *testSource) Changes() <-chan fuzzyfinder.ItemChange { return s.changes }
+
+func TestFind_WithItemSource(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetEventsV2(keys([]input{
+		// A no-op key to wait until the changes are applied.
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+		{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+	}...)...)
+
+	src := &testSource{
+		items:   []string{"foo", "bar", "baz"},
+		changes: make(chan fuzzyfinder.ItemChange),
+	}
+	go func() {
+		src.changes <- fuzzyfinder.ItemChange{Kind: fuzzyfinder.ItemsUpdated, Index: 1, Items: []string{"qux"}}
+		src.changes <- fuzzyfinder.ItemChange{Kind: fuzzyfinder.ItemsRemoved, Index: 0, Count: 1}
+		src.changes <- fuzzyfinder.ItemChange{Kind: fuzzyfinder.ItemsAppended, Index: 2, Items: []string{"quux"}}
+	}()
+
+	assertWithGolden(t, func(t *testing.T) string {
+		idx, err := f.Find(nil, nil, fuzzyfinder.WithItemSource(src))
+		if err != nil {
+			t.Fatalf("Find must not return an error, but got '%s'", err)
+		}
+		// The items are "qux", "baz" and "quux".
+		if idx != 1 {
+			t.Errorf("expected index: 1, but got %d", idx)
+		}
+		return term.GetResult()
+	})
+}
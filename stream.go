@@ -0,0 +1,244 @@
+package fuzzyfinder
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxReaderLineSize is the maximum size of a line read by FindReader.
+const maxReaderLineSize = 1024 * 1024
+
+// streamSource is a source which receives items from a channel. Received items
+// are passed to the finder and not kept by the source, so Len is always 0 and
+// Item is never called by the finder.
+type streamSource struct {
+	in <-chan string
+
+	mu   sync.RWMutex
+	done bool
+}
+
+func (s *streamSource) Len() int          { return 0 }
+func (s *streamSource) Item(i int) string { return "" }
+
+func (s *streamSource) Changes() <-chan ItemChange { return nil }
+
+func (s *streamSource) loading() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.done
+}
+
+// watch receives items from the channel until it is closed.
+func (s *streamSource) watch(ctx context.Context, apply func([]ItemChange)) {
+	defer func() {
+		s.mu.Lock()
+		s.done = true
+		s.mu.Unlock()
+	}()
+
+	var n int
+	receiveBatches(ctx, s.in, func(items []string) {
+		apply([]ItemChange{{Kind: ItemsAppended, Index: n, Items: items}})
+		n += len(items)
+	})
+}
+
+// receiveBatches receives strings from in until it is closed or ctx is done.
+// Received strings are passed to flush at intervals so that a fast producer
+// doesn't cause filtering for each string.
+func receiveBatches(ctx context.Context, in <-chan string, flush func([]string)) {
+	ticker := time.NewTicker(30 * time.Millisecond)
+	defer ticker.Stop()
+
+	var pending []string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s, ok := <-in:
+			if !ok {
+				if len(pending) > 0 {
+					flush(pending)
+				}
+				return
+			}
+			pending = append(pending, s)
+		case <-ticker.C:
+			if len(pending) > 0 {
+				flush(pending)
+				pending = nil
+			}
+		}
+	}
+}
+
+// findStream displays the UI immediately and adds items received from ch as
+// they arrive. It returns the indices of the selected items in arrival order
+// and the selected items.
+func (f *finder) findStream(ctx context.Context, ch <-chan string, opts []Option) ([]int, []string, error) {
+	src := &streamSource{in: ch}
+	opts = append([]Option{WithContext(ctx)}, opts...)
+	return f.findStrings(append(opts, WithItemSource(src)))
+}
+
+// findStrings is nearly the same as find for the items provided by an option
+// such as WithItemSource, but it also returns the selected items. The items
+// may be replaced while the finder runs, so the ones of the finder are used.
+func (f *finder) findStrings(opts []Option) ([]int, []string, error) {
+	idxs, err := f.find(nil, nil, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f.stateMu.RLock()
+	defer f.stateMu.RUnlock()
+	res := make([]string, len(idxs))
+	for i, idx := range idxs {
+		res[i] = f.state.items.clone(idx)
+	}
+	return idxs, res, nil
+}
+
+// findProduced runs produce in the background and passes the items sent by it
+// to findStream. The channel passed to produce is closed when produce returns.
+//
+// If produce fails and the user selects nothing, findProduced returns the
+// error of produce instead of ErrAbort. If produce panics, the finder is
+// closed and the panic is raised again in the calling goroutine, so that the
+// terminal is restored before the program crashes.
+func (f *finder) findProduced(produce func(ctx context.Context, ch chan<- string) error, opts []Option) ([]int, []string, error) {
+	opt := defaultOption
+	for _, o := range opts {
+		o(&opt)
+	}
+	parentContext := context.Background()
+	if opt.context != nil {
+		parentContext = opt.context
+	}
+	ctx, cancel := context.WithCancel(parentContext)
+	defer cancel()
+
+	ch := make(chan string)
+	errCh := make(chan error, 1)
+	panicCh := make(chan interface{}, 1)
+	go func() {
+		defer close(ch)
+		defer func() {
+			if r := recover(); r != nil {
+				panicCh <- r
+				cancel()
+			}
+		}()
+		if err := produce(ctx, ch); err != nil {
+			errCh <- err
+		}
+	}()
+
+	// ctx is derived from the context passed by WithContext, so it replaces it.
+	idxs, res, err := f.findStream(ctx, ch, append(opts, WithContext(ctx)))
+	select {
+	case r := <-panicCh:
+		panic(r)
+	default:
+	}
+	if err != nil {
+		select {
+		case perr := <-errCh:
+			return nil, nil, perr
+		default:
+		}
+	}
+	return idxs, res, err
+}
+
+// findReader reads lines from r in the background and passes them to findStream.
+func (f *finder) findReader(r io.Reader, opts []Option) ([]int, []string, error) {
+	return f.findProduced(func(ctx context.Context, ch chan<- string) error {
+		s := bufio.NewScanner(r)
+		s.Buffer(nil, maxReaderLineSize)
+		for s.Scan() {
+			select {
+			case <-ctx.Done():
+				return nil
+			case ch <- s.Text():
+			}
+		}
+		return errors.Wrap(s.Err(), "failed to read items")
+	}, opts)
+}
+
+// FindStream displays a UI that provides fuzzy finding against the strings
+// received from ch. The UI is displayed immediately and each item is added as
+// soon as it arrives. A spinner is displayed in the number line until ch is
+// closed.
+//
+// ctx is used as the parent context of the fuzzy finder. FindStream stops
+// receiving from ch when it returns.
+//
+// FindStream returns the index of the selected string in the order in which
+// the strings are received, and the selected string. It returns ErrAbort if a
+// call to FindStream is finished with no selection.
+func FindStream(ctx context.Context, ch <-chan string, opts ...Option) (int, string, error) {
+	f := newFinder()
+	return f.FindStream(ctx, ch, opts...)
+}
+
+func (f *finder) FindStream(ctx context.Context, ch <-chan string, opts ...Option) (int, string, error) {
+	idxs, res, err := f.findStream(ctx, ch, opts)
+	if err != nil {
+		return 0, "", err
+	}
+	return idxs[0], res[0], nil
+}
+
+// FindMultiStream is nearly the same as FindStream. The only difference from
+// FindStream is that the user can select multiple items at once, by using the
+// tab key.
+func FindMultiStream(ctx context.Context, ch <-chan string, opts ...Option) ([]int, []string, error) {
+	f := newFinder()
+	return f.FindMultiStream(ctx, ch, opts...)
+}
+
+func (f *finder) FindMultiStream(ctx context.Context, ch <-chan string, opts ...Option) ([]int, []string, error) {
+	opts = append(opts, withMulti())
+	return f.findStream(ctx, ch, opts)
+}
+
+// FindReader is nearly the same as FindStream, but each line read from r is
+// used as an item, and the returned index is the line number starting at 0.
+// The finder stops reading r when it returns, though a blocked Read call is
+// not interrupted.
+//
+// If reading r fails and the user selects nothing, FindReader returns the read
+// error instead of ErrAbort.
+func FindReader(r io.Reader, opts ...Option) (int, string, error) {
+	f := newFinder()
+	return f.FindReader(r, opts...)
+}
+
+func (f *finder) FindReader(r io.Reader, opts ...Option) (int, string, error) {
+	idxs, res, err := f.findReader(r, opts)
+	if err != nil {
+		return 0, "", err
+	}
+	return idxs[0], res[0], nil
+}
+
+// FindMultiReader is nearly the same as FindReader. The only difference from
+// FindReader is that the user can select multiple items at once, by using the
+// tab key.
+func FindMultiReader(r io.Reader, opts ...Option) ([]int, []string, error) {
+	f := newFinder()
+	return f.FindMultiReader(r, opts...)
+}
+
+func (f *finder) FindMultiReader(r io.Reader, opts ...Option) ([]int, []string, error) {
+	opts = append(opts, withMulti())
+	return f.findReader(r, opts)
+}
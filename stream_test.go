@@ -0,0 +1,143 @@
+package fuzzyfinder_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/go-cmp/cmp"
+	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
+	"github.com/pkg/errors"
+)
+
+func TestFindStream(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	events := append(runes("glow"), key(input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone}))
+	term.SetEventsV2(events...)
+
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, tr := range tracks {
+			ch <- tr.Name
+		}
+	}()
+
+	assertWithGolden(t, func(t *testing.T) string {
+		idx, item, err := f.FindStream(context.Background(), ch)
+		if err != nil {
+			t.Fatalf("FindStream must not return an error, but got '%s'", err)
+		}
+		if idx != 5 {
+			t.Errorf("expected index: 5, but got %d", idx)
+		}
+		if item != "glow" {
+			t.Errorf("expected item: glow, but got %s", item)
+		}
+		return term.GetResult()
+	})
+}
+
+func TestFindStream_WithAsyncPreviewWindow(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	events := make([]input, 8, 9)
+	for i := range events {
+		// No-op keys to wait while items are streamed.
+		events[i] = input{tcell.KeyCtrlE, 'E', tcell.ModCtrl}
+	}
+	events = append(events, input{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone})
+	term.SetEventsV2(keys(events...)...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan string)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- fmt.Sprintf("item %d", i):
+			}
+			time.Sleep(40 * time.Millisecond)
+		}
+	}()
+
+	var (
+		mu        sync.Mutex
+		calls     = map[int]int{}
+		completed = map[int]int{}
+	)
+	_, item, err := f.FindStream(ctx, ch, fuzzyfinder.WithAsyncPreviewWindow(func(ctx context.Context, i, w, h int) fuzzyfinder.PreviewResult {
+		mu.Lock()
+		calls[i]++
+		mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return fuzzyfinder.PreviewResult{}
+		case <-time.After(150 * time.Millisecond):
+		}
+		mu.Lock()
+		completed[i]++
+		mu.Unlock()
+		return fuzzyfinder.PreviewResult{Text: fmt.Sprintf("preview %d", i)}
+	}))
+	if err != nil {
+		t.Fatalf("FindStream must not return an error, but got '%s'", err)
+	}
+	if item != "item 0" {
+		t.Errorf("expected item: 'item 0', but got '%s'", item)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Appended items don't cancel the preview of the item under the cursor.
+	if calls[0] != 1 || completed[0] != 1 {
+		t.Errorf("the preview of item 0 must be computed once, but called %d times and completed %d times", calls[0], completed[0])
+	}
+}
+
+func TestFindMultiReader(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetEventsV2(keys([]input{
+		// A no-op key to wait until the items are received.
+		{tcell.KeyCtrlE, 'E', tcell.ModCtrl},
+		{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+		{tcell.KeyUp, rune(tcell.KeyUp), tcell.ModNone},
+		{tcell.KeyTab, rune(tcell.KeyTab), tcell.ModNone},
+		{tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone},
+	}...)...)
+
+	idxs, items, err := f.FindMultiReader(strings.NewReader("foo\nbar\nbaz\n"))
+	if err != nil {
+		t.Fatalf("FindMultiReader must not return an error, but got '%s'", err)
+	}
+	if diff := cmp.Diff([]int{0, 1}, idxs); diff != "" {
+		t.Errorf("wrong indices: \n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"foo", "bar"}, items); diff != "" {
+		t.Errorf("wrong result: \n%s", diff)
+	}
+}
+
+func TestFindReader_error(t *testing.T) {
+	t.Parallel()
+
+	f, term := fuzzyfinder.NewWithMockedTerminal()
+	term.SetEventsV2(key(input{tcell.KeyEsc, rune(tcell.KeyEsc), tcell.ModNone}))
+
+	_, _, err := f.FindReader(iotest.ErrReader(iotest.ErrTimeout))
+	if !errors.Is(err, iotest.ErrTimeout) {
+		t.Fatalf("FindReader must return the read error, but got '%s'", err)
+	}
+}
@@ -0,0 +1,81 @@
+package fuzzyfinder
+
+import "github.com/gdamore/tcell/v2"
+
+// Color represents a color of the terminal. The zero value is the default
+// color of the terminal.
+type Color int32
+
+// colorRGB is set to RGB colors. The lower 24 bits hold the RGB value.
+// Otherwise, a color holds its palette index plus one.
+const colorRGB Color = 1 << 24
+
+// The first 8 colors of the palette.
+const (
+	ColorDefault Color = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+// PaletteColor returns the n-th color of the 256-color palette.
+func PaletteColor(n int) Color {
+	return Color(n&0xff) + 1
+}
+
+// RGBColor returns a 24-bit color.
+func RGBColor(r, g, b uint8) Color {
+	return colorRGB | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+func (c Color) tcell() tcell.Color {
+	switch {
+	case c == ColorDefault:
+		return tcell.ColorDefault
+	case c&colorRGB != 0:
+		return tcell.NewHexColor(int32(c &^ colorRGB))
+	default:
+		return tcell.PaletteColor(int(c) - 1)
+	}
+}
+
+// Style represents the style of an item. The zero value is the default style.
+type Style struct {
+	Foreground    Color
+	Background    Color
+	Bold          bool
+	Dim           bool
+	Italic        bool
+	Underline     bool
+	StrikeThrough bool
+}
+
+func (s Style) tcell() tcell.Style {
+	return tcell.StyleDefault.
+		Foreground(s.Foreground.tcell()).
+		Background(s.Background.tcell()).
+		Bold(s.Bold).
+		Dim(s.Dim).
+		Italic(s.Italic).
+		Underline(s.Underline).
+		StrikeThrough(s.StrikeThrough)
+}
+
+// overlayStyle returns base overlaid with the colors and the attributes set in
+// over.
+func overlayStyle(base, over tcell.Style) tcell.Style {
+	fg, bg, attrs := over.Decompose()
+	if fg != tcell.ColorDefault {
+		base = base.Foreground(fg)
+	}
+	if bg != tcell.ColorDefault {
+		base = base.Background(bg)
+	}
+	_, _, baseAttrs := base.Decompose()
+	return base.Attributes(baseAttrs | attrs)
+}
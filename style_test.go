@@ -0,0 +1,34 @@
+package fuzzyfinder
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestColor_tcell(t *testing.T) {
+	cases := map[string]struct {
+		color    Color
+		expected tcell.Color
+	}{
+		"default": {color: ColorDefault, expected: tcell.ColorDefault},
+		"black":   {color: ColorBlack, expected: tcell.PaletteColor(0)},
+		"red":     {color: ColorRed, expected: tcell.PaletteColor(1)},
+		"white":   {color: ColorWhite, expected: tcell.PaletteColor(7)},
+		"palette": {color: PaletteColor(244), expected: tcell.PaletteColor(244)},
+		"rgb":     {color: RGBColor(0x12, 0x34, 0x56), expected: tcell.NewHexColor(0x123456)},
+		"rgb black": {
+			color:    RGBColor(0, 0, 0),
+			expected: tcell.NewHexColor(0),
+		},
+	}
+
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			if actual := c.color.tcell(); actual != c.expected {
+				t.Errorf("expected %v, but got %v", c.expected, actual)
+			}
+		})
+	}
+}
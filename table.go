@@ -0,0 +1,187 @@
+package fuzzyfinder
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ktr0731/go-fuzzyfinder/matching"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// Align represents the alignment of the cells in a column.
+type Align int
+
+const (
+	// AlignLeft aligns cells to the left. It is the default alignment.
+	AlignLeft Align = iota
+	// AlignRight aligns cells to the right.
+	AlignRight
+)
+
+// Column represents a column of the table displayed by WithTable.
+type Column struct {
+	// Header is displayed in the column header row.
+	Header string
+	// Align is the alignment of the header and the cells.
+	Align Align
+	// MaxWidth is the maximum width of the column. If it is 0, the width is
+	// not limited.
+	MaxWidth int
+	// NoMatch excludes the cells of the column from matching.
+	NoMatch bool
+}
+
+// tableGap is the number of spaces between columns.
+const tableGap = 2
+
+// tableSeparator joins the matched cells of a row into the text which is
+// matched against the query.
+const tableSeparator = "\t"
+
+// tableText returns the text of the i-th row which is matched against the
+// query.
+func (o *opt) tableText(i int) string {
+	cells := o.tableRow(i)
+	var (
+		b     strings.Builder
+		first = true
+	)
+	for c, col := range o.tableColumns {
+		if col.NoMatch {
+			continue
+		}
+		if !first {
+			b.WriteString(tableSeparator)
+		}
+		first = false
+		if c < len(cells) {
+			b.WriteString(cells[c])
+		}
+	}
+	return b.String()
+}
+
+// columnWidths returns the widths of the columns. They are computed from the
+// header and the rows displayed in the item lines, which have rows rows, and
+// they are shrunk to fit in width.
+func (f *finder) columnWidths(rows, width int) []int {
+	cols := f.opt.tableColumns
+	widths := make([]int, len(cols))
+	for c, col := range cols {
+		widths[c] = runewidth.StringWidth(col.Header)
+	}
+	first := f.state.y - f.state.cursorY
+	for i := 0; i < rows && first+i < f.state.matchedLen(); i++ {
+		cells := f.opt.tableRow(f.state.matchedAt(first + i).Idx)
+		for c := range cols {
+			if c < len(cells) {
+				widths[c] = max(widths[c], runewidth.StringWidth(cells[c]))
+			}
+		}
+	}
+
+	total := tableGap * (len(cols) - 1)
+	for c, col := range cols {
+		if col.MaxWidth > 0 {
+			widths[c] = min(widths[c], col.MaxWidth)
+		}
+		total += widths[c]
+	}
+	// Shrink the widest column one by one.
+	for total > width {
+		widest := 0
+		for c := range widths {
+			if widths[c] > widths[widest] {
+				widest = c
+			}
+		}
+		if widths[widest] <= 1 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// drawColumnHeader draws the column header row. The row is clipped at limit.
+func (f *finder) drawColumnHeader(widths []int, row, limit int) {
+	style := tcell.StyleDefault.Bold(true)
+	x := 2
+	for c, col := range f.opt.tableColumns {
+		f.drawCell(x, row, col.Header, widths[c], col.Align, limit, func(int, rune) tcell.Style {
+			return style
+		})
+		x += widths[c] + tableGap
+	}
+}
+
+// drawTableRow draws the cells of the matched item m. The row is clipped at
+// limit.
+func (f *finder) drawTableRow(m matching.Matched, widths []int, row, limit int, base tcell.Style, cursor bool) {
+	cells := f.opt.tableRow(m.Idx)
+	hl := f.newHighlighter(m)
+	// j is the index of the next rune in the text returned by tableText.
+	var (
+		j     int
+		first = true
+	)
+	x := 2
+	for c, col := range f.opt.tableColumns {
+		var cell string
+		if c < len(cells) {
+			cell = cells[c]
+		}
+		if !col.NoMatch {
+			if !first {
+				j += len(tableSeparator)
+			}
+			first = false
+		}
+		noMatch := col.NoMatch
+		f.drawCell(x, row, cell, widths[c], col.Align, limit, func(_ int, r rune) tcell.Style {
+			if noMatch {
+				return itemRuneStyle(base, false, cursor)
+			}
+			highlighted := hl.match(j, r)
+			j++
+			return itemRuneStyle(base, highlighted, cursor)
+		})
+		x += widths[c] + tableGap
+	}
+}
+
+// drawCell draws cell in [x, x+width) of row aligned by align. If the cell is
+// wider than width, it is shortened with "..". The part after limit is
+// clipped. styleOf is called for each rune of the cell in order.
+func (f *finder) drawCell(x, row int, cell string, width int, align Align, limit int, styleOf func(j int, r rune) tcell.Style) {
+	term := f.listPane()
+	cellWidth := runewidth.StringWidth(cell)
+	shortened := cellWidth > width
+	w := x
+	if !shortened && align == AlignRight {
+		w += width - cellWidth
+	}
+
+	var done bool
+	for j, r := range []rune(cell) {
+		style := styleOf(j, r)
+		if done {
+			continue
+		}
+		rw := runewidth.RuneWidth(r)
+		if shortened && w+rw > x+width-2 {
+			for ; w < min(x+width, limit); w++ {
+				term.SetContent(w, row, '.', nil, style)
+			}
+			done = true
+			continue
+		}
+		if w+rw > limit {
+			done = true
+			continue
+		}
+		term.SetContent(w, row, r, nil, style)
+		w += rw
+	}
+}
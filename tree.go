@@ -0,0 +1,284 @@
+package fuzzyfinder
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/ktr0731/go-fuzzyfinder/matching"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// TreeResult specifies which items of a tree passed by WithTree can be
+// returned by Find and FindMulti.
+type TreeResult int
+
+const (
+	// TreeResultNodes returns any item. It is the default.
+	TreeResultNodes TreeResult = iota
+	// TreeResultLeaves returns only items which have no children. Enter on
+	// an item which has children expands or collapses it instead, and Tab
+	// selects all the leaves under it.
+	TreeResultLeaves
+)
+
+// tree holds the parent relations of the items passed by WithTree.
+type tree struct {
+	// parents holds the parent of each item, or -1 for roots.
+	parents  []int
+	children [][]int
+	roots    []int
+}
+
+// newTree builds the tree of the current items.
+func (f *finder) newTree() *tree {
+	n := f.state.items.len()
+	t := &tree{parents: make([]int, n), children: make([][]int, n)}
+	for i := 0; i < n; i++ {
+		p := f.opt.treeParent(i)
+		if p < 0 || p >= n || p == i {
+			p = -1
+			t.roots = append(t.roots, i)
+		} else {
+			t.children[p] = append(t.children[p], i)
+		}
+		t.parents[i] = p
+	}
+	return t
+}
+
+// leaves returns the leaves under the i-th item, or i itself if it has no
+// children.
+func (t *tree) leaves(i int) []int {
+	if len(t.children[i]) == 0 {
+		return []int{i}
+	}
+	var res []int
+	for _, c := range t.children[i] {
+		res = append(res, t.leaves(c)...)
+	}
+	return res
+}
+
+// rows returns the items to display and their guides. If filtering is false,
+// the items whose ancestors are all expanded are returned. Otherwise, matched
+// and their ancestors are returned. The items are in the preorder if topDown
+// is true, or in the reverse preorder otherwise, so that the roots are drawn at
+// the top.
+func (t *tree) rows(matched []matching.Matched, filtering, topDown bool, expanded map[int]bool) ([]matching.Matched, map[int]string) {
+	var keep []bool
+	byIdx := make(map[int]matching.Matched, len(matched))
+	if filtering {
+		keep = make([]bool, len(t.parents))
+		for _, m := range matched {
+			byIdx[m.Idx] = m
+			for i := m.Idx; i != -1 && !keep[i]; i = t.parents[i] {
+				keep[i] = true
+			}
+		}
+	}
+	visible := func(nodes []int) []int {
+		if !filtering {
+			return nodes
+		}
+		var res []int
+		for _, i := range nodes {
+			if keep[i] {
+				res = append(res, i)
+			}
+		}
+		return res
+	}
+
+	var res []matching.Matched
+	guides := make(map[int]string)
+	var walk func(nodes []int, prefix string, root bool)
+	walk = func(nodes []int, prefix string, root bool) {
+		nodes = visible(nodes)
+		for k, i := range nodes {
+			guide, childPrefix := prefix, prefix
+			if !root {
+				if k == len(nodes)-1 {
+					guide += "└ "
+					childPrefix += "  "
+				} else {
+					guide += "├ "
+					childPrefix += "│ "
+				}
+			}
+			var children []int
+			if filtering || expanded[i] {
+				children = visible(t.children[i])
+			}
+			switch {
+			case len(children) > 0:
+				guide += "▾ "
+			case len(t.children[i]) > 0:
+				guide += "▸ "
+			}
+
+			m, ok := byIdx[i]
+			if !ok {
+				// Ancestors which are not matched are displayed for context.
+				m = matching.Matched{Idx: i, Pos: [2]int{-1, -1}} //nolint:exhaustivestruct
+			}
+			res = append(res, m)
+			guides[i] = guide
+			walk(children, childPrefix, false)
+		}
+	}
+	walk(t.roots, "", true)
+
+	if topDown {
+		return res, guides
+	}
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res, guides
+}
+
+// filterTree is filter for WithTree. While filtering, the cursor is moved to
+// the best matched item. Otherwise, it is kept on the same item if possible.
+func (f *finder) filterTree() {
+	f.stateMu.RLock()
+	t := f.newTree()
+	filtering := len(f.state.input) > 0
+	var matched []matching.Matched
+	if filtering {
+		matched = matching.FindAllFunc(string(f.state.input), f.state.items.len(), f.state.items.at, matching.WithMode(matching.Mode(f.opt.mode)))
+	}
+	rows, guides := t.rows(matched, filtering, f.itemsTopDown(), f.state.expanded)
+	version := f.state.version
+	f.stateMu.RUnlock()
+
+	f.stateMu.Lock()
+	defer f.stateMu.Unlock()
+	if version != f.state.version {
+		// The items are changed while filtering, so filter them again.
+		defer f.requestFilter()
+		return
+	}
+
+	cursorIdx := -1
+	switch {
+	case len(matched) > 0:
+		cursorIdx = matched[0].Idx
+	case !f.state.allMatched && 0 <= f.state.y && f.state.y < f.state.matchedLen():
+		cursorIdx = f.state.matchedAt(f.state.y).Idx
+	}
+	f.state.tree = t
+	f.state.treeGuides = guides
+	f.state.matched = rows
+	f.state.allMatched = false
+	f.clampCursor()
+	for i, m := range rows {
+		if m.Idx == cursorIdx {
+			f.moveCursorTo(i)
+			break
+		}
+	}
+}
+
+// treeKey handles the keys for WithTree. Shift-Right expands the item pointed
+// by the cursor, and Shift-Left collapses it or moves the cursor to its
+// parent. It reports whether e is handled.
+func (f *finder) treeKey(e *tcell.EventKey) bool {
+	if f.state.tree == nil || f.state.y < 0 || f.state.y >= f.state.matchedLen() || e.Modifiers()&tcell.ModShift == 0 {
+		return false
+	}
+	idx := f.state.matchedAt(f.state.y).Idx
+	switch e.Key() {
+	case tcell.KeyRight:
+		if len(f.state.tree.children[idx]) > 0 && !f.state.expanded[idx] {
+			f.state.expanded[idx] = true
+			f.requestFilter()
+		}
+	case tcell.KeyLeft:
+		if f.state.expanded[idx] {
+			delete(f.state.expanded, idx)
+			f.requestFilter()
+			return true
+		}
+		p := f.state.tree.parents[idx]
+		for i := 0; i < f.state.matchedLen(); i++ {
+			if p != -1 && f.state.matchedAt(i).Idx == p {
+				f.moveCursorTo(i)
+				break
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// toggleTreeNode expands or collapses the item pointed by the cursor instead
+// of accepting it if TreeResultLeaves is passed and the item has children. It
+// reports whether the item is toggled.
+func (f *finder) toggleTreeNode() bool {
+	if f.state.tree == nil || f.opt.treeResult != TreeResultLeaves || f.state.matchedLen() == 0 {
+		return false
+	}
+	if f.opt.multi && len(f.state.selection) > 0 {
+		return false
+	}
+	idx := f.state.matchedAt(f.state.y).Idx
+	if len(f.state.tree.children[idx]) == 0 {
+		return false
+	}
+	if f.state.expanded[idx] {
+		delete(f.state.expanded, idx)
+	} else {
+		f.state.expanded[idx] = true
+	}
+	f.requestFilter()
+	return true
+}
+
+// selectTreeLeaves selects all the leaves under the item pointed by the
+// cursor, or unselects them if all of them are selected, if TreeResultLeaves
+// is passed and the item has children. It reports whether the selections are
+// changed.
+func (f *finder) selectTreeLeaves() bool {
+	if f.state.tree == nil || f.opt.treeResult != TreeResultLeaves {
+		return false
+	}
+	idx := f.state.matchedAt(f.state.y).Idx
+	if len(f.state.tree.children[idx]) == 0 {
+		return false
+	}
+	leaves := f.state.tree.leaves(idx)
+	all := true
+	for _, l := range leaves {
+		if _, ok := f.state.selection[l]; !ok {
+			all = false
+			break
+		}
+	}
+	for _, l := range leaves {
+		if all {
+			delete(f.state.selection, l)
+		} else if _, ok := f.state.selection[l]; !ok {
+			f.state.selection[l] = f.state.selectionIdx
+			f.state.selectionIdx++
+		}
+	}
+	return true
+}
+
+// drawTreeGuide draws the guide of the idx-th item from x in row and returns
+// the position next to it. The guide is clipped at maxWidth.
+func (f *finder) drawTreeGuide(idx, x, row, maxWidth int, cursor bool) int {
+	term := f.listPane()
+	style := tcell.StyleDefault.Dim(true)
+	if cursor {
+		style = style.Background(tcell.ColorBlack)
+	}
+	for _, r := range f.state.treeGuides[idx] {
+		rw := runewidth.RuneWidth(r)
+		if x+rw > maxWidth {
+			break
+		}
+		term.SetContent(x, row, r, nil, style)
+		x += rw
+	}
+	return x
+}